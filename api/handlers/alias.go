@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/types"
+	log "github.com/sirupsen/logrus"
+)
+
+type putAliasRequest struct {
+	Routes []*types.FnAliasRoute `json:"routes"`
+}
+
+var (
+	ErrInvalidAliasName   = errors.New("the alias name must not be empty, numeric nor contain '" + revisionSeparator + "'")
+	ErrInvalidAliasRoutes = errors.New("the alias must have at least one route, and the weights of the routes must be positive and sum to 100")
+)
+
+func PutFunctionAliasHandler(s state.State) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, fnName, aliasName := c.Request.Context(), c.Param("name"), c.Param("alias")
+
+		if err := validateAliasName(aliasName); err != nil {
+			c.JSON(http.StatusBadRequest, makeApiError(err))
+			return
+		}
+
+		// Parse the request body
+		data := &putAliasRequest{}
+		if err := c.BindJSON(data); err != nil {
+			log.Errorf("Failed to decode put alias request body: %v", err)
+			c.JSON(http.StatusBadRequest, makeApiError(err))
+			return
+		}
+
+		fn, err := s.Get(ctx, fnName)
+		if err != nil && !errors.Is(err, state.ErrKeyNotFound) {
+			log.Error(err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		if fn == nil {
+			c.JSON(http.StatusNotFound, makeApiError(ErrFunctionNotFound))
+			return
+		}
+
+		total := 0
+		for _, route := range data.Routes {
+			if route == nil || route.Weight <= 0 {
+				c.JSON(http.StatusBadRequest, makeApiError(ErrInvalidAliasRoutes))
+				return
+			}
+			if fn.GetRevision(route.Revision) == nil {
+				c.JSON(http.StatusBadRequest, makeApiError(ErrRevisionNotFound))
+				return
+			}
+			total += route.Weight
+		}
+
+		if total != 100 {
+			c.JSON(http.StatusBadRequest, makeApiError(ErrInvalidAliasRoutes))
+			return
+		}
+
+		fn = fn.WithAlias(aliasName, &types.FnAlias{Routes: data.Routes})
+		if err := s.Set(ctx, fn); err != nil {
+			log.Errorf("Failed to update function into the state: %v", err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		log.Infof("Alias '%s' of function '%s' successfully updated", aliasName, fnName)
+		c.JSON(http.StatusOK, fn)
+	}
+}
+
+func DeleteFunctionAliasHandler(s state.State) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, fnName, aliasName := c.Request.Context(), c.Param("name"), c.Param("alias")
+
+		fn, err := s.Get(ctx, fnName)
+		if err != nil && !errors.Is(err, state.ErrKeyNotFound) {
+			log.Error(err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		if fn == nil {
+			c.JSON(http.StatusNotFound, makeApiError(ErrFunctionNotFound))
+			return
+		}
+
+		if _, exists := fn.Aliases[aliasName]; !exists {
+			c.JSON(http.StatusNotFound, makeApiError(ErrAliasNotFound))
+			return
+		}
+
+		if err := s.Set(ctx, fn.WithAlias(aliasName, nil)); err != nil {
+			log.Errorf("Failed to update function into the state: %v", err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		log.Infof("Alias '%s' of function '%s' successfully deleted", aliasName, fnName)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// validateAliasName ensure that the alias can't be confused with a revision number
+// when parsing a function reference.
+func validateAliasName(name string) error {
+	if name == "" || strings.Contains(name, revisionSeparator) {
+		return ErrInvalidAliasName
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return ErrInvalidAliasName
+	}
+	return nil
+}
@@ -3,21 +3,29 @@ package handlers
 import (
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/orchestration/kubernetes"
 	"github.com/morty-faas/controller/state"
 	"github.com/morty-faas/controller/types"
 	"github.com/sirupsen/logrus"
 )
 
 type createFnRequest struct {
-	Name  string `json:"name"`
-	Image string `json:"image"`
+	Name         string `json:"name"`
+	Image        string `json:"image"`
+	MinInstances int    `json:"minInstances"`
+	MaxInstances int    `json:"maxInstances"`
+	IdleTimeout  int    `json:"idleTimeout"`
 }
 
 var (
-	ErrNameConflict = errors.New("a function already exists with the given name")
+	ErrNameConflict         = errors.New("a function already exists with the given name")
+	ErrInvalidName          = errors.New("the function name must not be empty nor contain '" + revisionSeparator + "'")
+	ErrInvalidScalingBounds = errors.New("the instances bounds must be positive, and minInstances must not be greater than maxInstances")
+	ErrInvalidIdleTimeout   = errors.New("the idle timeout must be positive")
 )
 
 func CreateFunctionHandler(state state.State, orch orchestration.Orchestrator) gin.HandlerFunc {
@@ -40,12 +48,38 @@ func CreateFunctionHandler(state state.State, orch orchestration.Orchestrator) g
 			return
 		}
 
-		fn := &types.Function{
-			Name:     data.Name,
-			ImageURL: data.Image,
+		if data.Name == "" || strings.Contains(data.Name, revisionSeparator) {
+			logrus.Errorf("Invalid function name: %s", data.Name)
+			c.JSON(http.StatusBadRequest, makeApiError(ErrInvalidName))
+			return
+		}
+
+		if data.MinInstances < 0 || data.MaxInstances < 0 || (data.MaxInstances > 0 && data.MinInstances > data.MaxInstances) {
+			logrus.Errorf("Invalid instances bounds: min %d, max %d", data.MinInstances, data.MaxInstances)
+			c.JSON(http.StatusBadRequest, makeApiError(ErrInvalidScalingBounds))
+			return
 		}
 
+		if data.IdleTimeout < 0 {
+			logrus.Errorf("Invalid idle timeout: %d", data.IdleTimeout)
+			c.JSON(http.StatusBadRequest, makeApiError(ErrInvalidIdleTimeout))
+			return
+		}
+
+		// A newly created function starts with its first revision
+		fn := (&types.Function{
+			Name:         data.Name,
+			MinInstances: data.MinInstances,
+			MaxInstances: data.MaxInstances,
+			IdleTimeout:  data.IdleTimeout,
+		}).NewRevision(data.Image)
+
 		fn, err := orch.CreateFunction(ctx, fn)
+		if errors.Is(err, kubernetes.ErrInvalidFunctionName) {
+			logrus.Errorf("Invalid function name: %s", data.Name)
+			c.JSON(http.StatusBadRequest, makeApiError(err))
+			return
+		}
 		if err != nil {
 			logrus.Errorf("Failed to create function into the orchestrator: %v", err)
 			c.JSON(http.StatusInternalServerError, makeApiError(err))
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/morty-faas/controller/internal/testutil"
+	"github.com/morty-faas/controller/orchestration/kubernetes"
+	"github.com/morty-faas/controller/state/memory"
+	"github.com/morty-faas/controller/types"
+)
+
+func TestCreateFunctionHandler(t *testing.T) {
+	invalidName := fmt.Errorf("%w: must consist of lower case alphanumeric characters", kubernetes.ErrInvalidFunctionName)
+
+	tests := []struct {
+		name       string
+		body       string
+		orch       *testutil.Orchestrator
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "created",
+			body:       `{"name":"weatho","image":"morty/weatho:v1"}`,
+			orch:       &testutil.Orchestrator{},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid name",
+			body:       `{"name":"weatho@v1","image":"morty/weatho:v1"}`,
+			orch:       &testutil.Orchestrator{},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   fmt.Sprintf(`{"message":"%s"}`, ErrInvalidName),
+		},
+		{
+			name:       "name refused by the orchestrator",
+			body:       `{"name":"Weatho_App","image":"morty/weatho:v1"}`,
+			orch:       &testutil.Orchestrator{CreateErr: invalidName},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   fmt.Sprintf(`{"message":"%s"}`, invalidName),
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := memory.NewState(func(ref *types.FnInstanceRef) {})
+			t.Cleanup(func() { s.Close() })
+
+			router := gin.New()
+			router.POST("/functions", CreateFunctionHandler(s, tt.orch))
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/functions", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Fatalf("body = %s, want %s", rec.Body, tt.wantBody)
+			}
+		})
+	}
+}
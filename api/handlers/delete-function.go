@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/state"
+	log "github.com/sirupsen/logrus"
+)
+
+func DeleteFunctionHandler(s state.State, orch orchestration.Orchestrator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, fnName := c.Request.Context(), c.Param("name")
+
+		log.Debugf("Delete function '%s'", fnName)
+
+		fn, err := s.Get(ctx, fnName)
+		if err != nil && !errors.Is(err, state.ErrKeyNotFound) {
+			log.Error(err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		if fn == nil {
+			c.JSON(http.StatusNotFound, makeApiError(ErrFunctionNotFound))
+			return
+		}
+
+		// Remove the function from the orchestrator first, so if anything goes wrong
+		// during the teardown, the function is still known by the controller and the
+		// user is able to retry the deletion.
+		if err := orch.DeleteFunction(ctx, fn); err != nil {
+			log.Errorf("Failed to delete function from the orchestrator: %v", err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		// The instances of the function have been torn down along with the function
+		records, err := s.ListInstances(ctx)
+		if err != nil {
+			log.Errorf("Failed to list instances from the state: %v", err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+		for _, record := range records {
+			if record.FunctionName != fn.Name {
+				continue
+			}
+			if err := s.DeleteInstance(ctx, record.Ref()); err != nil {
+				log.Errorf("Failed to remove instance from the state: %v", err)
+				c.JSON(http.StatusInternalServerError, makeApiError(err))
+				return
+			}
+		}
+
+		if err := s.Delete(ctx, fn.Name); err != nil {
+			log.Errorf("Failed to remove function from the state: %v", err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		log.Infof("Function '%s' successfully deleted", fnName)
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/morty-faas/controller/reconciliation"
+	log "github.com/sirupsen/logrus"
+)
+
+// DriftHandler reports the current drift between the state and the orchestrator, without correcting it.
+func DriftHandler(r *reconciliation.Reconciler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		drift, err := r.Diff(c.Request.Context())
+		if err != nil {
+			log.Errorf("Failed to compute the drift between the state and the orchestrator: %v", err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, drift)
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/morty-faas/controller/internal/testutil"
+	"github.com/morty-faas/controller/leader"
+	"github.com/morty-faas/controller/reconciliation"
+	"github.com/morty-faas/controller/scaling"
+	"github.com/morty-faas/controller/state/memory"
+	"github.com/morty-faas/controller/types"
+)
+
+func TestDriftHandler(t *testing.T) {
+	weatho := &types.Function{Id: "weatho-r1", Name: "weatho", Revision: 1}
+	orphaned := testutil.NewInstance(weatho, "orphaned")
+
+	tests := []struct {
+		name       string
+		orch       *testutil.Orchestrator
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "drift",
+			orch:       &testutil.Orchestrator{Functions: []*types.Function{weatho}, Instances: []*types.FnInstance{orphaned}},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"missingFunctions":["weatho"],"unprovisionedFunctions":[],"orphanedInstances":[{"functionId":"weatho-r1","instanceId":"orphaned"}],"staleInstances":[]}`,
+		},
+		{
+			name:       "orchestrator unavailable",
+			orch:       &testutil.Orchestrator{ListErr: errors.New("orchestrator unavailable")},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"message":"orchestrator unavailable"}`,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := memory.NewState(func(ref *types.FnInstanceRef) {})
+			t.Cleanup(func() { s.Close() })
+
+			elector := leader.NewElector(&leader.Config{}, s)
+			autoscaler := scaling.NewAutoscaler(&scaling.Config{}, s, tt.orch, testutil.NewTracker(), elector)
+			r := reconciliation.NewReconciler(&reconciliation.Config{Interval: time.Minute}, s, tt.orch, autoscaler, elector)
+
+			router := gin.New()
+			router.GET("/_/drift", DriftHandler(r))
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/_/drift", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if body := rec.Body.String(); body != tt.wantBody {
+				t.Fatalf("body = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
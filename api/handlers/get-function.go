@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/types"
+	log "github.com/sirupsen/logrus"
+)
+
+type getFnResponse struct {
+	Function  *types.Function `json:"function"`
+	Instances []*fnInstance   `json:"instances"`
+}
+
+type fnInstance struct {
+	Id       string `json:"id"`
+	Revision int    `json:"revision"`
+	Endpoint string `json:"endpoint"`
+	// WarmUntil is the date at which the instance will be deleted if it doesn't receive
+	// any invocation. It is nil if the instance never received an invocation.
+	WarmUntil *time.Time `json:"warmUntil,omitempty"`
+}
+
+func GetFunctionHandler(s state.State, orch orchestration.Orchestrator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, fnName := c.Request.Context(), c.Param("name")
+
+		fn, err := s.Get(ctx, fnName)
+		if err != nil && !errors.Is(err, state.ErrKeyNotFound) {
+			log.Error(err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		if fn == nil {
+			c.JSON(http.StatusNotFound, makeApiError(ErrFunctionNotFound))
+			return
+		}
+
+		instances, err := orch.GetFunctionInstances(ctx, fn)
+		if err != nil {
+			log.Errorf("Failed to retrieve function instances from the orchestrator: %v", err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		res := &getFnResponse{
+			Function:  fn,
+			Instances: []*fnInstance{},
+		}
+
+		for _, instance := range instances {
+			in := &fnInstance{
+				Id:       instance.Id,
+				Revision: instance.Function.Revision,
+				Endpoint: instance.Endpoint.String(),
+			}
+
+			warmUntil, err := s.GetExpiry(ctx, instance.Ref())
+			if err == nil {
+				in.WarmUntil = &warmUntil
+			} else if !errors.Is(err, state.ErrKeyNotFound) {
+				log.Warnf("Failed to retrieve expiry of instance %s: %v", instance.Id, err)
+			}
+
+			res.Instances = append(res.Instances, in)
+		}
+
+		c.JSON(http.StatusOK, res)
+	}
+}
@@ -1,22 +1,79 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/morty-faas/controller/orchestration"
 	"github.com/morty-faas/controller/state"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	statusUp   = "UP"
+	statusDown = "DOWN"
+
+	// readinessCheckTimeout is the maximum duration allowed for a dependency to answer
+	readinessCheckTimeout = 2 * time.Second
 )
 
 type healthcheckResponse struct {
 	Status string `json:"status"`
+	// Checks contains the status of each dependency of the controller, indexed by name
+	Checks map[string]*dependencyStatus `json:"checks,omitempty"`
 }
 
-func HealthHandler(state state.State) gin.HandlerFunc {
+type dependencyStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// LivenessHandler reports if the controller process is alive. It doesn't check
+// any dependency, so a failing dependency will not cause the controller to be restarted.
+func LivenessHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Currently, we consider that the server is always healthy
-		// TODO: check for state health (and underlying orchestrator ?)
 		c.JSON(http.StatusOK, &healthcheckResponse{
-			Status: "UP",
+			Status: statusUp,
 		})
 	}
 }
+
+// ReadinessHandler reports if the controller is able to serve requests, by checking
+// that the state engine and the orchestrator are reachable.
+func ReadinessHandler(s state.State, orch orchestration.Orchestrator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		res := &healthcheckResponse{
+			Status: statusUp,
+			Checks: map[string]*dependencyStatus{
+				"state":        checkDependency(ctx, s.Ping),
+				"orchestrator": checkDependency(ctx, orch.Ping),
+			},
+		}
+
+		code := http.StatusOK
+		for name, check := range res.Checks {
+			if check.Status != statusUp {
+				log.Warnf("Readiness check failed for dependency '%s': %s", name, check.Error)
+				res.Status = statusDown
+				code = http.StatusServiceUnavailable
+			}
+		}
+
+		c.JSON(code, res)
+	}
+}
+
+// checkDependency is a helper function to run a dependency check with a timeout
+func checkDependency(ctx context.Context, ping func(context.Context) error) *dependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	if err := ping(ctx); err != nil {
+		return &dependencyStatus{Status: statusDown, Error: err.Error()}
+	}
+	return &dependencyStatus{Status: statusUp}
+}
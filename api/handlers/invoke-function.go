@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -11,10 +12,24 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/metrics"
+	"github.com/morty-faas/controller/readiness"
+	"github.com/morty-faas/controller/scaling"
 	"github.com/morty-faas/controller/state"
 	"github.com/morty-faas/controller/types"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// instanceClient is the HTTP client used to reach the function instances
+	instanceClient = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
+	tracer = otel.Tracer("github.com/morty-faas/controller/api/handlers")
 )
 
 var (
@@ -22,14 +37,21 @@ var (
 	ErrFunctionCantBeMarkedAsHealthy = errors.New("one or more instances of the function can't be marked as healthy")
 )
 
-func InvokeFunctionHandler(s state.State, orch orchestration.Orchestrator) gin.HandlerFunc {
+func InvokeFunctionHandler(s state.State, autoscaler *scaling.Autoscaler, tracker *readiness.Tracker, idleTimeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, fnName := c.Request.Context(), c.Param("name")
+		ctx, start := c.Request.Context(), time.Now()
 
-		log.Debugf("Invoke function '%s'", fnName)
+		ref, err := parseFnRef(c.Param("name"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, makeApiError(err))
+			return
+		}
+		fnName := ref.Name
+
+		log.Debugf("Invoke function '%s' (revision: %d, alias: '%s')", fnName, ref.Revision, ref.Alias)
 
 		fn, err := s.Get(ctx, fnName)
-		if err != nil {
+		if err != nil && !errors.Is(err, state.ErrKeyNotFound) {
 			log.Error(err)
 			c.JSON(http.StatusInternalServerError, makeApiError(err))
 			return
@@ -40,49 +62,99 @@ func InvokeFunctionHandler(s state.State, orch orchestration.Orchestrator) gin.H
 			return
 		}
 
-		instance, err := orch.GetFunctionInstance(ctx, fn)
+		// Metrics are only recorded for existing functions to keep the labels cardinality bounded
+		defer func() {
+			metrics.Invocations.WithLabelValues(fnName, strconv.Itoa(c.Writer.Status())).Inc()
+			metrics.InvocationDuration.WithLabelValues(fnName).Observe(time.Since(start).Seconds())
+		}()
+
+		if fn, err = ref.resolve(fn); err != nil {
+			c.JSON(http.StatusNotFound, makeApiError(err))
+			return
+		}
+
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attribute.String("function.name", fnName), attribute.Int("function.revision", fn.Revision))
+
+		instance, err := getFunctionInstance(ctx, autoscaler, fn)
 		if err != nil {
 			log.Error(err)
 			c.JSON(http.StatusInternalServerError, makeApiError(err))
 			return
 		}
+		defer autoscaler.Release(instance)
 
-		proxy := makeProxy(instance)
-
-		// Healthcheck the instance
-		// Perform healthcheck against the Alpha agent
-		// If alpha doesn't anwser to our requests, it probably that
-		// the VM isn't ready yet to receive our requests.
-		const maxHealthcheckRetries = 10
-		healthcheck := instance.Endpoint.String() + "/_/health"
-		for i := 0; i < maxHealthcheckRetries; i++ {
-			log.Debugf("Performing healthcheck request on Alpha: %s", healthcheck)
-			if _, err := http.Get(healthcheck); err != nil {
-				if i == maxHealthcheckRetries-1 {
-					log.Errorf("failed to perform healthcheck on Alpha: %v", err)
-					c.JSON(http.StatusServiceUnavailable, makeApiError(ErrFunctionCantBeMarkedAsHealthy))
-					return
-				}
-				time.Sleep(1 * time.Second)
-				continue
-			}
-			log.Infof("Function '%s' is healthy and ready to receive requests", fnName)
-			break
-		}
+		proxy := makeProxy(autoscaler, instance)
 
-		// Each invocation warn up function for 15 minutes
-		if err := s.SetWithExpiry(ctx, instance.Id, 15*time.Minute); err != nil {
-			log.Error(err)
-			c.JSON(http.StatusInternalServerError, makeApiError(err))
+		if err := waitReady(ctx, tracker, instance); err != nil {
+			log.Errorf("failed to perform healthcheck on Alpha: %v", err)
+			autoscaler.Forget(instance.Ref())
+			c.JSON(http.StatusServiceUnavailable, makeApiError(ErrFunctionCantBeMarkedAsHealthy))
 			return
 		}
 
-		proxy.ServeHTTP(c.Writer, c.Request)
+		// Each invocation warm up the instance for the idle timeout of the function. The instances of the latest
+		// revision of the functions declaring minimum instances don't expire, the autoscaler scales them down when
+		// they are idle.
+		if fn.MinWarmInstances() == 0 {
+			if err := s.SetWithExpiry(ctx, instance.Ref(), fn.KeepWarmDuration(idleTimeout)); err != nil {
+				log.Error(err)
+				c.JSON(http.StatusInternalServerError, makeApiError(err))
+				return
+			}
+		}
+
+		ctx, proxySpan := tracer.Start(ctx, "Proxy")
+		defer proxySpan.End()
+
+		proxy.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
+}
+
+// getFunctionInstance is a helper function to acquire an instance of the function within a dedicated span
+func getFunctionInstance(ctx context.Context, autoscaler *scaling.Autoscaler, fn *types.Function) (*types.FnInstance, error) {
+	ctx, span := tracer.Start(ctx, "GetFunctionInstance")
+	defer span.End()
+
+	instance, err := autoscaler.Acquire(ctx, fn)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("instance.id", instance.Id))
+	return instance, nil
+}
+
+// waitReady waits for the instance to be ready to receive requests within a dedicated span.
+// Instances already known to be healthy are returned immediately by the tracker.
+func waitReady(ctx context.Context, tracker *readiness.Tracker, instance *types.FnInstance) error {
+	ctx, span := tracer.Start(ctx, "Healthcheck")
+	defer span.End()
+
+	span.SetAttributes(attribute.Bool("healthcheck.skipped", tracker.IsReady(instance.Id)))
+
+	if err := tracker.WaitReady(ctx, instance); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
 	}
+	return nil
 }
 
-func makeProxy(instance *types.FnInstance) *httputil.ReverseProxy {
+func makeProxy(autoscaler *scaling.Autoscaler, instance *types.FnInstance) *httputil.ReverseProxy {
 	proxy := httputil.NewSingleHostReverseProxy(instance.Endpoint)
+	// The transport propagates the trace context to the Alpha agent
+	proxy.Transport = instanceClient.Transport
+
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		log.Errorf("Failed to proxy invocation to instance %s: %v", instance.Id, err)
+		metrics.ProxyErrors.WithLabelValues(instance.Function.Name).Inc()
+		// The instance may be gone, so no more invocations are sent to it
+		autoscaler.Forget(instance.Ref())
+		w.WriteHeader(http.StatusBadGateway)
+	}
 
 	// Modify the response the response so we can extract
 	// the payload from the Alpha response and return it to the caller
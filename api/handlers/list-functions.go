@@ -2,20 +2,27 @@ package handlers
 
 import (
 	"net/http"
+	"sort"
 
 	"github.com/gin-gonic/gin"
-	"github.com/morty-faas/controller/orchestration"
 	"github.com/morty-faas/controller/state"
+	log "github.com/sirupsen/logrus"
 )
 
-func ListFunctionsHandler(s state.State, orch orchestration.Orchestrator) gin.HandlerFunc {
+func ListFunctionsHandler(s state.State) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		functions, err := orch.GetFunctions(c.Request.Context())
+		functions, err := s.List(c.Request.Context())
 		if err != nil {
+			log.Errorf("Failed to list functions from the state: %v", err)
 			c.JSON(http.StatusInternalServerError, makeApiError(err))
 			return
 		}
 
+		// The order of the functions returned by the state engines isn't guaranteed
+		sort.Slice(functions, func(i, j int) bool {
+			return functions[i].Name < functions[j].Name
+		})
+
 		c.JSON(http.StatusOK, functions)
 	}
 }
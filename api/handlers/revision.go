@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/types"
+)
+
+// revisionSeparator separates the function name from a revision number
+// in the path of a request, e.g: `/functions/weatho@3/invoke`
+const revisionSeparator = "@"
+
+var (
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrAliasNotFound    = errors.New("alias not found")
+)
+
+// fnRef is a reference to a function, optionally targeting a specific revision or alias.
+type fnRef struct {
+	Name     string
+	Revision int
+	Alias    string
+}
+
+// parseFnRef parses references such as `weatho`, `weatho@3` or `weatho@stable`.
+// A suffix made only of digits targets a revision, anything else targets an alias.
+func parseFnRef(raw string) (*fnRef, error) {
+	name, suffix, found := strings.Cut(raw, revisionSeparator)
+	if !found {
+		return &fnRef{Name: name}, nil
+	}
+
+	if suffix == "" {
+		return nil, fmt.Errorf("invalid function reference '%s': missing revision or alias", raw)
+	}
+
+	if revision, err := strconv.Atoi(suffix); err == nil {
+		if revision < 1 {
+			return nil, fmt.Errorf("invalid revision '%s': must be a positive integer", suffix)
+		}
+		return &fnRef{Name: name, Revision: revision}, nil
+	}
+
+	return &fnRef{Name: name, Alias: suffix}, nil
+}
+
+// resolve returns a copy of the function pinned to the revision targeted by the reference.
+// When the reference targets an alias, the revision is picked according to the alias weights.
+// By default, the latest revision of the function is returned.
+func (r *fnRef) resolve(fn *types.Function) (*types.Function, error) {
+	revision := r.Revision
+	if r.Alias != "" {
+		alias, exists := fn.Aliases[r.Alias]
+		if !exists {
+			return nil, ErrAliasNotFound
+		}
+		revision = alias.Pick()
+	}
+
+	if revision == 0 {
+		return fn, nil
+	}
+
+	pinned := fn.AtRevision(revision)
+	if pinned == nil {
+		return nil, ErrRevisionNotFound
+	}
+	return pinned, nil
+}
+
+// deployRevision creates a new revision of the function using the given image, deploy it
+// into the orchestrator and record it into the state. The new revision becomes the latest
+// revision of the function.
+func deployRevision(ctx context.Context, s state.State, orch orchestration.Orchestrator, fn *types.Function, image string) (*types.Function, error) {
+	fn, err := orch.UpdateFunction(ctx, fn.NewRevision(image))
+	if err != nil {
+		return nil, fmt.Errorf("failed to deploy revision into the orchestrator: %v", err)
+	}
+
+	if err := s.Set(ctx, fn); err != nil {
+		return nil, fmt.Errorf("failed to record revision into the state: %v", err)
+	}
+
+	return fn, nil
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/state"
+	log "github.com/sirupsen/logrus"
+)
+
+type rollbackFnRequest struct {
+	Revision int `json:"revision"`
+}
+
+func RollbackFunctionHandler(s state.State, orch orchestration.Orchestrator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, fnName := c.Request.Context(), c.Param("name")
+
+		// Parse the request body
+		data := &rollbackFnRequest{}
+		if err := c.BindJSON(data); err != nil {
+			log.Errorf("Failed to decode rollback function request body: %v", err)
+			c.JSON(http.StatusBadRequest, makeApiError(err))
+			return
+		}
+
+		fn, err := s.Get(ctx, fnName)
+		if err != nil && !errors.Is(err, state.ErrKeyNotFound) {
+			log.Error(err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		if fn == nil {
+			c.JSON(http.StatusNotFound, makeApiError(ErrFunctionNotFound))
+			return
+		}
+
+		target := fn.GetRevision(data.Revision)
+		if target == nil {
+			c.JSON(http.StatusNotFound, makeApiError(ErrRevisionNotFound))
+			return
+		}
+
+		// Revisions are immutable: rolling back produces a new revision
+		// with the same definition as the targeted one.
+		log.Debugf("Rollback function '%s' to revision %d", fnName, target.Number)
+
+		fn, err = deployRevision(ctx, s, orch, fn, target.ImageURL)
+		if err != nil {
+			log.Errorf("Failed to rollback function: %v", err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		log.Infof("Function '%s' successfully rolled back to revision %d (new revision %d)", fnName, target.Number, fn.Revision)
+		c.JSON(http.StatusOK, fn)
+	}
+}
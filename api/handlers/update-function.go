@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/state"
+	log "github.com/sirupsen/logrus"
+)
+
+type updateFnRequest struct {
+	Image string `json:"image"`
+}
+
+var (
+	ErrImageRequired = errors.New("the function image is required")
+)
+
+func UpdateFunctionHandler(s state.State, orch orchestration.Orchestrator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, fnName := c.Request.Context(), c.Param("name")
+
+		// Parse the request body
+		data := &updateFnRequest{}
+		if err := c.BindJSON(data); err != nil {
+			log.Errorf("Failed to decode update function request body: %v", err)
+			c.JSON(http.StatusBadRequest, makeApiError(err))
+			return
+		}
+
+		if data.Image == "" {
+			c.JSON(http.StatusBadRequest, makeApiError(ErrImageRequired))
+			return
+		}
+
+		fn, err := s.Get(ctx, fnName)
+		if err != nil && !errors.Is(err, state.ErrKeyNotFound) {
+			log.Error(err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		if fn == nil {
+			c.JSON(http.StatusNotFound, makeApiError(ErrFunctionNotFound))
+			return
+		}
+
+		log.Debugf("Update function '%s' image from '%s' to '%s'", fnName, fn.ImageURL, data.Image)
+
+		fn, err = deployRevision(ctx, s, orch, fn, data.Image)
+		if err != nil {
+			log.Errorf("Failed to update function: %v", err)
+			c.JSON(http.StatusInternalServerError, makeApiError(err))
+			return
+		}
+
+		log.Infof("Function '%s' successfully updated to revision %d", fnName, fn.Revision)
+		c.JSON(http.StatusOK, fn)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os/signal"
@@ -11,37 +12,69 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/morty-faas/controller/api/handlers"
 	"github.com/morty-faas/controller/config"
+	"github.com/morty-faas/controller/leader"
+	"github.com/morty-faas/controller/metrics"
 	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/readiness"
+	"github.com/morty-faas/controller/reconciliation"
+	"github.com/morty-faas/controller/scaling"
 	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/tracing"
 	"github.com/morty-faas/controller/types"
 	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type server struct {
 	cfg   *config.Config
 	state state.State
 	orch  orchestration.Orchestrator
+	// shutdownTracing flushes the pending spans on shutdown
+	shutdownTracing tracing.ShutdownFunc
+	readiness       *readiness.Tracker
+	autoscaler      *scaling.Autoscaler
+	elector         *leader.Elector
+	reconciler      *reconciliation.Reconciler
+	// ready is closed once the server is fully initialized
+	ready chan struct{}
 }
 
 // New initializes a new API server.
 // The method will return an error if the bootstrap process encounters one.
 func New(cfg *config.Config) (*server, error) {
+	// Tracing must be initialized first, so the instrumented
+	// state and orchestrator use the configured tracer provider
+	shutdownTracing, err := tracing.Init(&cfg.Tracing)
+	if err != nil {
+		return nil, err
+	}
+
 	orch, err := cfg.OrchestratorFactory()
 	if err != nil {
 		return nil, err
 	}
 
-	state, err := cfg.StateFactory(func(functionName string) {
-		if err := orch.DeleteFunctionInstance(context.Background(), &types.Function{Name: functionName}); err != nil {
-			log.Errorf("Failed to automatically delete instance for function %s: %v", functionName, err)
-		}
-	})
+	srv := &server{
+		cfg:             cfg,
+		orch:            orch,
+		shutdownTracing: shutdownTracing,
+		readiness:       readiness.NewTracker(&cfg.Readiness),
+		ready:           make(chan struct{}),
+	}
+
+	srv.state, err = cfg.StateFactory(srv.onInstanceExpired)
 	if err != nil {
 		return nil, err
 	}
 
-	srv := &server{cfg, state, orch}
+	srv.elector = leader.NewElector(&cfg.LeaderElection, srv.state)
+	srv.autoscaler = scaling.NewAutoscaler(&cfg.Scaling, srv.state, orch, srv.readiness, srv.elector)
+	srv.reconciler = reconciliation.NewReconciler(&cfg.Reconciliation, srv.state, orch, srv.autoscaler, srv.elector)
+
+	// The expiry callback may be called as soon as the state is created, so it waits for this signal
+	close(srv.ready)
+
 	srv.getInitialState()
 	return srv, nil
 }
@@ -67,6 +100,10 @@ func (s *server) ListenAndServe() {
 		}
 	}()
 
+	go s.elector.Run(ctx)
+	go s.autoscaler.Run(ctx)
+	go s.reconciler.Run(ctx)
+
 	// Wait for an interrupt signal
 	<-ctx.Done()
 
@@ -82,32 +119,95 @@ func (s *server) ListenAndServe() {
 		log.Fatalf("HTTP server forced to shutdown: %v", err)
 	}
 
+	// Another controller can take over the background tasks right away
+	s.elector.Resign(ctx)
+
+	// Stop handling the instances expiration, and release the state connections
+	if err := s.state.Close(); err != nil {
+		log.Errorf("Failed to close the state: %v", err)
+	}
+
+	if err := s.shutdownTracing(ctx); err != nil {
+		log.Errorf("Failed to flush pending spans: %v", err)
+	}
+
 }
 
 // makeRouter initializes the application router and return it
 func (s *server) makeRouter() http.Handler {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
+	r.Use(otelgin.Middleware(tracing.ServiceName))
 
 	// Health
-	r.GET("/_/health", handlers.HealthHandler(s.state))
+	r.GET("/_/live", handlers.LivenessHandler())
+	r.GET("/_/ready", handlers.ReadinessHandler(s.state, s.orch))
+	// Kept for backward compatibility, prefer using the liveness probe
+	r.GET("/_/health", handlers.LivenessHandler())
+	// Drift between the state and the orchestrator
+	r.GET("/_/drift", handlers.DriftHandler(s.reconciler))
+
+	// Metrics
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// Functions
-	r.GET("/functions", handlers.ListFunctionsHandler(s.state, s.orch))
+	r.GET("/functions", handlers.ListFunctionsHandler(s.state))
 	r.POST("/functions", handlers.CreateFunctionHandler(s.state, s.orch))
-	r.Any("/functions/:name/invoke", handlers.InvokeFunctionHandler(s.state, s.orch))
+	r.GET("/functions/:name", handlers.GetFunctionHandler(s.state, s.orch))
+	r.PUT("/functions/:name", handlers.UpdateFunctionHandler(s.state, s.orch))
+	r.DELETE("/functions/:name", handlers.DeleteFunctionHandler(s.state, s.orch))
+	r.POST("/functions/:name/rollback", handlers.RollbackFunctionHandler(s.state, s.orch))
+	r.PUT("/functions/:name/aliases/:alias", handlers.PutFunctionAliasHandler(s.state))
+	r.DELETE("/functions/:name/aliases/:alias", handlers.DeleteFunctionAliasHandler(s.state))
+	r.Any("/functions/:name/invoke", handlers.InvokeFunctionHandler(s.state, s.autoscaler, s.readiness, s.cfg.IdleTimeout))
 
 	return r
 }
 
+// onInstanceExpired is called by the state once the keep-warm period of an instance
+// expired. The instance is torn down and removed from the state by the leader only,
+// as every controller sharing the state is notified.
+func (s *server) onInstanceExpired(ref *types.FnInstanceRef) {
+	<-s.ready
+	ctx := context.Background()
+
+	s.autoscaler.Forget(ref)
+
+	if !s.elector.IsLeader() {
+		log.Tracef("Expired instance %s is left to the leader", ref)
+		return
+	}
+
+	err := s.orch.DeleteFunctionInstance(ctx, ref)
+	if errors.Is(err, orchestration.ErrInstanceNotFound) {
+		log.Debugf("Expired instance %s is already gone", ref)
+	} else if err != nil {
+		log.Errorf("Failed to automatically delete expired instance %s: %v", ref, err)
+		return
+	}
+
+	if err := s.state.DeleteInstance(ctx, ref); err != nil {
+		log.Errorf("Failed to remove expired instance %s from the state: %v", ref, err)
+	}
+}
+
 // getInitialState will try to retrieve the existing functions by calling the
 // underlying orchestrator, and will populate the server state engine if
 // functions are found.
 func (s *server) getInitialState() {
 	ctx := context.Background()
 	if functions, err := s.orch.GetFunctions(ctx); err == nil {
+		// Functions already known by the state are kept as is, as they hold
+		// settings the orchestrator isn't aware of (e.g. the minimum instances)
+		var missing []*types.Function
+		for _, fn := range functions {
+			if existing, err := s.state.Get(ctx, fn.Name); err != nil || existing == nil {
+				missing = append(missing, fn)
+			}
+		}
+
 		// If functions are found, we need to populate the state with them
-		if errs := s.state.SetMultiple(ctx, functions); errs != nil && len(errs) > 0 {
+		if errs := s.state.SetMultiple(ctx, missing); errs != nil && len(errs) > 0 {
 			logrus.Warnf("Failed to populate state with existing functions: %v", err)
 		}
 	} else {
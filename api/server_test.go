@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/morty-faas/controller/internal/testutil"
+	"github.com/morty-faas/controller/leader"
+	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/scaling"
+	"github.com/morty-faas/controller/state/memory"
+	"github.com/morty-faas/controller/types"
+)
+
+// newTestServer initializes a server backed by the memory state and the given orchestrator.
+// The instances notified as expired by the state are handled by the server, then collected.
+func newTestServer(t *testing.T, orch orchestration.Orchestrator) (*server, testutil.Expirations) {
+	t.Helper()
+
+	s := &server{
+		orch:      orch,
+		readiness: testutil.NewTracker(),
+		ready:     make(chan struct{}),
+	}
+	expired := testutil.NewExpirations()
+	s.state = memory.NewState(func(ref *types.FnInstanceRef) {
+		s.onInstanceExpired(ref)
+		expired.Callback(ref)
+	})
+	t.Cleanup(func() { s.state.Close() })
+
+	s.elector = leader.NewElector(&leader.Config{}, s.state)
+	s.autoscaler = scaling.NewAutoscaler(&scaling.Config{}, s.state, orch, s.readiness, s.elector)
+	close(s.ready)
+
+	return s, expired
+}
+
+// recordInstances registers the instances into the state, with a keep-warm period
+func recordInstances(t *testing.T, s *server, instances ...*types.FnInstance) {
+	t.Helper()
+
+	ctx := context.Background()
+	for _, instance := range instances {
+		if err := s.state.SetInstance(ctx, instance.Record()); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.state.SetWithExpiry(ctx, instance.Ref(), time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+var testFunction = &types.Function{Id: "fn-1", Name: "weatho", Revision: 1}
+
+func TestOnInstanceExpiredDeletesReferencedInstance(t *testing.T) {
+	orch := &testutil.Orchestrator{}
+	s, _ := newTestServer(t, orch)
+
+	expired, sibling := testutil.NewInstance(testFunction, "instance-1"), testutil.NewInstance(testFunction, "instance-2")
+	recordInstances(t, s, expired, sibling)
+
+	s.onInstanceExpired(expired.Ref())
+
+	if deleted := orch.Deleted(); len(deleted) != 1 || deleted[0] != expired.Id {
+		t.Fatalf("deleted instances = %v, want only %s", deleted, expired.Id)
+	}
+
+	records, err := s.state.ListInstances(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].InstanceId != sibling.Id {
+		t.Fatalf("recorded instances = %+v, want only %s", records, sibling.Ref())
+	}
+}
+
+func TestOnInstanceExpiredInstanceAlreadyGone(t *testing.T) {
+	orch := &testutil.Orchestrator{DeleteErr: orchestration.ErrInstanceNotFound}
+	s, _ := newTestServer(t, orch)
+
+	expired := testutil.NewInstance(testFunction, "instance-1")
+	recordInstances(t, s, expired)
+
+	s.onInstanceExpired(expired.Ref())
+
+	records, err := s.state.ListInstances(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("recorded instances = %+v, want none", records)
+	}
+}
+
+func TestOnInstanceExpiredRetriesOnFailure(t *testing.T) {
+	orch := &testutil.Orchestrator{DeleteErr: errors.New("orchestrator unavailable")}
+	s, expired := newTestServer(t, orch)
+
+	instance := testutil.NewInstance(testFunction, "instance-1")
+	recordInstances(t, s, instance)
+	if err := s.state.SetWithExpiry(context.Background(), instance.Ref(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// The instance failed to be torn down, so it is kept into the state and notified again
+	expired.Wait(t, instance.Ref(), 5*time.Second)
+	expired.Wait(t, instance.Ref(), 5*time.Second)
+
+	if deleted := orch.Deleted(); len(deleted) != 2 {
+		t.Fatalf("deleted instances = %v, want 2 deletion attempts of %s", deleted, instance.Id)
+	}
+
+	records, err := s.state.ListInstances(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("recorded instances = %+v, want %s", records, instance.Ref())
+	}
+}
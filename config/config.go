@@ -1,31 +1,58 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/morty-faas/controller/leader"
 	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/orchestration/kubernetes"
 	"github.com/morty-faas/controller/orchestration/rik"
+	"github.com/morty-faas/controller/readiness"
+	"github.com/morty-faas/controller/reconciliation"
+	"github.com/morty-faas/controller/scaling"
 	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/state/bolt"
+	"github.com/morty-faas/controller/state/etcd"
 	"github.com/morty-faas/controller/state/memory"
+	"github.com/morty-faas/controller/state/postgres"
 	"github.com/morty-faas/controller/state/redis"
+	"github.com/morty-faas/controller/tracing"
 	log "github.com/sirupsen/logrus"
 	"github.com/thomasgouveia/go-config"
 )
 
 type (
 	Config struct {
-		Port         int          `yaml:"port"`
-		Orchestrator Orchestrator `yaml:"orchestrator"`
-		State        State        `yaml:"state"`
+		Port           int                   `yaml:"port"`
+		IdleTimeout    time.Duration         `yaml:"idleTimeout"`
+		Orchestrator   Orchestrator          `yaml:"orchestrator"`
+		State          State                 `yaml:"state"`
+		Tracing        tracing.Config        `yaml:"tracing"`
+		Readiness      readiness.Config      `yaml:"readiness"`
+		Scaling        scaling.Config        `yaml:"scaling"`
+		LeaderElection leader.Config         `yaml:"leaderElection"`
+		Reconciliation reconciliation.Config `yaml:"reconciliation"`
 	}
 
 	Orchestrator struct {
-		Rik rik.Config `yaml:"rik"`
+		Rik        rik.Config        `yaml:"rik"`
+		Kubernetes kubernetes.Config `yaml:"kubernetes"`
 	}
 
 	State struct {
-		Redis redis.Config `yaml:"redis"`
+		Redis    redis.Config    `yaml:"redis"`
+		Etcd     etcd.Config     `yaml:"etcd"`
+		Bolt     bolt.Config     `yaml:"bolt"`
+		Postgres postgres.Config `yaml:"postgres"`
 	}
 )
 
+var (
+	ErrInvalidRenewInterval = errors.New("configuration key `leaderElection.renewInterval` must be lower than `leaderElection.leaseDuration`")
+)
+
 var loaderOptions = &config.Options[Config]{
 	Format: config.YAML,
 
@@ -39,11 +66,28 @@ var loaderOptions = &config.Options[Config]{
 
 	// Default configuration
 	Default: &Config{
-		Port: 8080,
-		Orchestrator: Orchestrator{
-			Rik: rik.Config{
-				Cluster: "http://localhost:5000",
-			},
+		Port:        8080,
+		IdleTimeout: 15 * time.Minute,
+		Tracing: tracing.Config{
+			SampleRatio: 1,
+		},
+		Readiness: readiness.Config{
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     2 * time.Second,
+			Timeout:        30 * time.Second,
+		},
+		Scaling: scaling.Config{
+			TargetConcurrency: 10,
+			MaxInstances:      10,
+			ScaleDownDelay:    5 * time.Minute,
+			Interval:          30 * time.Second,
+		},
+		LeaderElection: leader.Config{
+			LeaseDuration: 15 * time.Second,
+			RenewInterval: 5 * time.Second,
+		},
+		Reconciliation: reconciliation.Config{
+			Interval: time.Minute,
 		},
 	},
 }
@@ -62,9 +106,54 @@ func Load() (*Config, error) {
 
 	log.Debugf("Loaded configuration: %+v", cfg)
 
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
+// validate checks the values that would make the controller fail at runtime
+func (c *Config) validate() error {
+	durations := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"readiness.initialBackoff", c.Readiness.InitialBackoff},
+		{"readiness.maxBackoff", c.Readiness.MaxBackoff},
+		{"readiness.timeout", c.Readiness.Timeout},
+		{"scaling.scaleDownDelay", c.Scaling.ScaleDownDelay},
+		{"scaling.interval", c.Scaling.Interval},
+		{"leaderElection.leaseDuration", c.LeaderElection.LeaseDuration},
+		{"leaderElection.renewInterval", c.LeaderElection.RenewInterval},
+		{"reconciliation.interval", c.Reconciliation.Interval},
+	}
+	for _, d := range durations {
+		if d.value <= 0 {
+			return fmt.Errorf("configuration key `%s` must be a positive duration, got %v", d.key, d.value)
+		}
+	}
+
+	counts := []struct {
+		key   string
+		value int
+	}{
+		{"scaling.targetConcurrency", c.Scaling.TargetConcurrency},
+		{"scaling.maxInstances", c.Scaling.MaxInstances},
+	}
+	for _, n := range counts {
+		if n.value <= 0 {
+			return fmt.Errorf("configuration key `%s` must be a positive number, got %d", n.key, n.value)
+		}
+	}
+
+	if c.LeaderElection.RenewInterval >= c.LeaderElection.LeaseDuration {
+		return ErrInvalidRenewInterval
+	}
+
+	return nil
+}
+
 // StateFactory initializes a new state implementation based on the configuration.
 func (c *Config) StateFactory(expiryCallback state.FnExpiryCallback) (state.State, error) {
 	log.Debugf("Applying state factory based on configuration")
@@ -76,13 +165,35 @@ func (c *Config) StateFactory(expiryCallback state.FnExpiryCallback) (state.Stat
 		return redis.NewState(&c.State.Redis, expiryCallback)
 	}
 
+	if isDefined(c.State.Etcd) {
+		return etcd.NewState(&c.State.Etcd, expiryCallback)
+	}
+
+	if isDefined(c.State.Bolt) {
+		return bolt.NewState(&c.State.Bolt, expiryCallback)
+	}
+
+	if isDefined(c.State.Postgres) {
+		return postgres.NewState(&c.State.Postgres, expiryCallback)
+	}
+
 	// By default, we will use a in memory state engine if no configuration
 	// is provided by the user.
-	return memory.NewState(), nil
+	return memory.NewState(expiryCallback), nil
 }
 
 // OrchestratorFactory initializes a new orchestrator implementation based on the configuration.
 func (c *Config) OrchestratorFactory() (orchestration.Orchestrator, error) {
 	log.Debugf("Applying orchestrator factory based on configuration")
+	if err := ensureKeyHasSingleSubKey(c.Orchestrator); err != nil {
+		return nil, err
+	}
+
+	if isDefined(c.Orchestrator.Kubernetes) {
+		return kubernetes.NewOrchestrator(&c.Orchestrator.Kubernetes)
+	}
+
+	// By default, we will target a RIK cluster if no
+	// other orchestrator is configured by the user.
 	return rik.NewOrchestrator(&c.Orchestrator.Rik)
 }
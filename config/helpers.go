@@ -11,7 +11,26 @@ func isDefined(v any) bool {
 	if v == nil {
 		return false
 	}
-	return !reflect.ValueOf(v).IsZero()
+	return !isZero(reflect.ValueOf(v))
+}
+
+// isZero reports whether v is the zero value of its type. Unlike reflect.Value.IsZero,
+// empty slices and maps are considered as zero, as the configuration loader may
+// initialize them even if they aren't defined by the user.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isZero(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return v.IsZero()
+	}
 }
 
 // ensureKeyHasSingleSubKey check using the reflection API, if the key has only one sub key defined.
@@ -27,7 +46,7 @@ func ensureKeyHasSingleSubKey(key interface{}) error {
 
 		// If the field isn't zero (e.g: {}) and
 		// we didn't match a sub-key already
-		if !f.IsZero() {
+		if !isZero(f) {
 			fields = append(fields, fName)
 			if len(fields) > 1 {
 				return fmt.Errorf("found multiple sub-keys defined for configuration key `%s`, but only one is allowed: %v", strings.ToLower(v.Type().Name()), fields)
@@ -0,0 +1,194 @@
+// Package testutil provides the fakes shared by the tests of the controller packages.
+package testutil
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/readiness"
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/types"
+)
+
+// Orchestrator is a fake orchestrator running the given functions and instances. The methods
+// that aren't implemented panic, as the embedded orchestrator is nil.
+type Orchestrator struct {
+	orchestration.Orchestrator
+
+	mu sync.Mutex
+	// Functions are the functions provisioned into the orchestrator
+	Functions []*types.Function
+	// Instances are the running instances, across all the functions
+	Instances []*types.FnInstance
+	// ListErr fails the listing of the functions when set
+	ListErr error
+	// CreateErr fails the function creations when set
+	CreateErr error
+	// DeleteErr fails the instance deletions when set
+	DeleteErr error
+	deleted   []types.FnInstanceRef
+}
+
+func (o *Orchestrator) GetFunctions(ctx context.Context) ([]*types.Function, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.ListErr != nil {
+		return nil, o.ListErr
+	}
+	return o.Functions, nil
+}
+
+func (o *Orchestrator) CreateFunction(ctx context.Context, fn *types.Function) (*types.Function, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.CreateErr != nil {
+		return nil, o.CreateErr
+	}
+	o.Functions = append(o.Functions, fn)
+	return fn, nil
+}
+
+func (o *Orchestrator) GetFunctionInstances(ctx context.Context, fn *types.Function) ([]*types.FnInstance, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var instances []*types.FnInstance
+	for _, instance := range o.Instances {
+		if instance.Function.Name == fn.Name {
+			instances = append(instances, instance)
+		}
+	}
+	return instances, nil
+}
+
+// DeleteFunctionInstance records the deletion attempt, and stops the instance unless DeleteErr is set.
+func (o *Orchestrator) DeleteFunctionInstance(ctx context.Context, ref *types.FnInstanceRef) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.deleted = append(o.deleted, *ref)
+	if o.DeleteErr != nil {
+		return o.DeleteErr
+	}
+
+	for i, instance := range o.Instances {
+		if *instance.Ref() == *ref {
+			o.Instances = append(o.Instances[:i:i], o.Instances[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Deleted returns the identifiers of the instances whose deletion was attempted, sorted.
+func (o *Orchestrator) Deleted() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	ids := []string{}
+	for _, ref := range o.deleted {
+		ids = append(ids, ref.InstanceId)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Locker wraps a state with an in-memory lock, so it can be shared between controllers.
+type Locker struct {
+	state.State
+
+	mu        sync.Mutex
+	holder    string
+	expiresAt time.Time
+}
+
+func (l *Locker) AcquireLock(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.holder != "" && l.holder != holder && time.Now().Before(l.expiresAt) {
+		return false, nil
+	}
+	l.holder, l.expiresAt = holder, time.Now().Add(ttl)
+	return true, nil
+}
+
+func (l *Locker) ReleaseLock(ctx context.Context, name, holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.holder == holder {
+		l.holder = ""
+	}
+	return nil
+}
+
+// Holder returns the current owner of the lock, or an empty string if it is free.
+func (l *Locker) Holder() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if time.Now().After(l.expiresAt) {
+		return ""
+	}
+	return l.holder
+}
+
+// Expirations collects the instances notified by the expiry callback of a state.
+type Expirations chan *types.FnInstanceRef
+
+// NewExpirations initializes a collector of the expired instances.
+func NewExpirations() Expirations {
+	return make(Expirations, 16)
+}
+
+// Callback is the expiry callback to give to the state.
+func (e Expirations) Callback(ref *types.FnInstanceRef) {
+	e <- ref
+}
+
+// Wait fails the test if the instance isn't notified as expired within the timeout.
+func (e Expirations) Wait(t *testing.T, ref *types.FnInstanceRef, timeout time.Duration) {
+	t.Helper()
+
+	select {
+	case got := <-e:
+		if *got != *ref {
+			t.Fatalf("expired instance = %s, want %s", got, ref)
+		}
+	case <-time.After(timeout):
+		t.Fatalf("instance %s didn't expire in time", ref)
+	}
+}
+
+// None fails the test if an instance is notified as expired within the duration.
+func (e Expirations) None(t *testing.T, d time.Duration) {
+	t.Helper()
+
+	select {
+	case got := <-e:
+		t.Fatalf("instance %s expired, want none", got)
+	case <-time.After(d):
+	}
+}
+
+// NewTracker initializes a readiness tracker which probes the instances without waiting.
+func NewTracker() *readiness.Tracker {
+	return readiness.NewTracker(&readiness.Config{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Timeout: time.Second})
+}
+
+// NewInstance returns an instance of the function, served on localhost.
+func NewInstance(fn *types.Function, instanceId string) *types.FnInstance {
+	return &types.FnInstance{
+		Id:       instanceId,
+		Function: fn,
+		Endpoint: &url.URL{Scheme: "http", Host: "localhost:8080"},
+	}
+}
@@ -0,0 +1,141 @@
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/morty-faas/controller/metrics"
+	"github.com/morty-faas/controller/state"
+	log "github.com/sirupsen/logrus"
+)
+
+// lockName is the name of the lock owned by the leader
+const lockName = "leader"
+
+// Config hold the configuration about the leader election
+type Config struct {
+	// LeaseDuration is the duration after which the leadership is lost if it isn't renewed
+	LeaseDuration time.Duration `yaml:"leaseDuration"`
+	// RenewInterval is the interval at which the leader renews its lease,
+	// and the other controllers try to acquire it. It must be lower than LeaseDuration.
+	RenewInterval time.Duration `yaml:"renewInterval"`
+}
+
+// Elector elects a single leader between the controllers sharing the same state, so the
+// background tasks (e.g. the instances expiration) are only handled once. If the state
+// engine can't be shared between controllers, the controller is always the leader.
+type Elector struct {
+	cfg    *Config
+	locker state.Locker
+	id     string
+	// leaseEnd is the unix time in nanoseconds until which the leadership is held
+	leaseEnd atomic.Int64
+}
+
+// NewElector initializes a new leader elector based on the given configuration.
+func NewElector(cfg *Config, s state.State) *Elector {
+	e := &Elector{cfg: cfg, id: newHolderId()}
+
+	if locker, ok := s.(state.Locker); ok {
+		e.locker = locker
+	} else {
+		log.Debug("State engine can't be shared between controllers, leader election is disabled")
+		metrics.Leader.Set(1)
+	}
+
+	return e
+}
+
+// IsLeader returns true if the controller currently holds the leadership.
+func (e *Elector) IsLeader() bool {
+	if e.locker == nil {
+		return true
+	}
+	return time.Now().UnixNano() < e.leaseEnd.Load()
+}
+
+// Run campaigns for the leadership, and renews it once acquired, until the context is done.
+func (e *Elector) Run(ctx context.Context) {
+	if e.locker == nil {
+		return
+	}
+
+	ticker := time.NewTicker(e.cfg.RenewInterval)
+	defer ticker.Stop()
+
+	for {
+		e.campaign(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Resign releases the leadership if it is held, so another controller can take over
+// without waiting for the lease to expire.
+func (e *Elector) Resign(ctx context.Context) {
+	if e.locker == nil || !e.IsLeader() {
+		return
+	}
+
+	e.setLeaseEnd(0)
+	if err := e.locker.ReleaseLock(ctx, lockName, e.id); err != nil {
+		log.Errorf("Failed to release the leadership: %v", err)
+	}
+}
+
+// campaign is a helper function to acquire or renew the leadership lease
+func (e *Elector) campaign(ctx context.Context) {
+	// The lease is considered to start before the request is sent,
+	// so we never assume to hold it longer than the state does
+	start := time.Now()
+
+	acquired, err := e.locker.AcquireLock(ctx, lockName, e.id, e.cfg.LeaseDuration)
+	switch {
+	case err != nil:
+		// The leadership is kept until the current lease ends,
+		// as no other controller can acquire it in the meantime
+		log.Errorf("Failed to renew the leadership lease: %v", err)
+	case acquired:
+		e.setLeaseEnd(start.Add(e.cfg.LeaseDuration).UnixNano())
+	default:
+		e.setLeaseEnd(0)
+	}
+}
+
+// setLeaseEnd is a helper function to update the end of the lease and report the leadership changes
+func (e *Elector) setLeaseEnd(end int64) {
+	wasLeader := e.IsLeader()
+	e.leaseEnd.Store(end)
+
+	if isLeader := e.IsLeader(); isLeader != wasLeader {
+		if isLeader {
+			log.Infof("Controller %s is now the leader", e.id)
+			metrics.Leader.Set(1)
+		} else {
+			log.Infof("Controller %s is no longer the leader", e.id)
+			metrics.Leader.Set(0)
+		}
+	}
+}
+
+// newHolderId is a helper function to generate an identifier unique to this controller
+func newHolderId() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "controller"
+	}
+
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		log.Warnf("Failed to generate a random controller identifier: %v", err)
+	}
+	return hostname + "-" + hex.EncodeToString(b)
+}
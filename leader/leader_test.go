@@ -0,0 +1,146 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/morty-faas/controller/internal/testutil"
+	"github.com/morty-faas/controller/state/memory"
+	"github.com/morty-faas/controller/types"
+)
+
+var testConfig = &Config{LeaseDuration: 100 * time.Millisecond, RenewInterval: 10 * time.Millisecond}
+
+// unreachable is a shared state which can't be reached
+type unreachable struct {
+	testutil.Locker
+}
+
+func (*unreachable) AcquireLock(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestIsLeaderWithoutLocker(t *testing.T) {
+	s := memory.NewState(func(ref *types.FnInstanceRef) {})
+	t.Cleanup(func() { s.Close() })
+
+	e := NewElector(testConfig, s)
+	if !e.IsLeader() {
+		t.Fatal("expected the controller to be the leader when the state can't be shared")
+	}
+
+	e.Resign(context.Background())
+	if !e.IsLeader() {
+		t.Fatal("expected the controller to remain the leader after resigning")
+	}
+}
+
+func TestCampaign(t *testing.T) {
+	ctx := context.Background()
+	locker := &testutil.Locker{}
+	first, second := NewElector(testConfig, locker), NewElector(testConfig, locker)
+
+	if first.IsLeader() {
+		t.Fatal("expected the controller not to be the leader before campaigning")
+	}
+
+	first.campaign(ctx)
+	second.campaign(ctx)
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatalf("leaders = %t, %t, want only the first controller", first.IsLeader(), second.IsLeader())
+	}
+
+	// The leader keeps the leadership as long as it renews its lease
+	for i := 0; i < 3; i++ {
+		time.Sleep(testConfig.LeaseDuration / 2)
+		first.campaign(ctx)
+		second.campaign(ctx)
+	}
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatalf("leaders = %t, %t, want only the first controller", first.IsLeader(), second.IsLeader())
+	}
+}
+
+func TestLeadershipLostOnLeaseExpiry(t *testing.T) {
+	ctx := context.Background()
+	locker := &testutil.Locker{}
+	first, second := NewElector(testConfig, locker), NewElector(testConfig, locker)
+
+	first.campaign(ctx)
+	time.Sleep(testConfig.LeaseDuration)
+
+	if first.IsLeader() {
+		t.Fatal("expected the leadership to be lost once the lease expired")
+	}
+	second.campaign(ctx)
+	if !second.IsLeader() {
+		t.Fatal("expected another controller to take over the expired leadership")
+	}
+}
+
+func TestLeadershipKeptUntilLeaseEndOnError(t *testing.T) {
+	ctx := context.Background()
+	locker := &unreachable{}
+	e := NewElector(testConfig, locker)
+
+	// The lease was acquired before the state became unreachable
+	e.setLeaseEnd(time.Now().Add(testConfig.LeaseDuration).UnixNano())
+	e.campaign(ctx)
+	if !e.IsLeader() {
+		t.Fatal("expected the leadership to be kept until the lease ends")
+	}
+
+	time.Sleep(testConfig.LeaseDuration)
+	e.campaign(ctx)
+	if e.IsLeader() {
+		t.Fatal("expected the leadership to be lost once the lease ended")
+	}
+}
+
+func TestResign(t *testing.T) {
+	ctx := context.Background()
+	locker := &testutil.Locker{}
+	first, second := NewElector(testConfig, locker), NewElector(testConfig, locker)
+
+	first.campaign(ctx)
+	first.Resign(ctx)
+
+	if first.IsLeader() {
+		t.Fatal("expected the controller not to be the leader after resigning")
+	}
+	if holder := locker.Holder(); holder != "" {
+		t.Fatalf("lock held by %s, want it to be released", holder)
+	}
+
+	// Another controller takes over without waiting for the lease to expire
+	second.campaign(ctx)
+	if !second.IsLeader() {
+		t.Fatal("expected another controller to take over the leadership")
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := NewElector(testConfig, &testutil.Locker{})
+
+	stopped := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(stopped)
+	}()
+
+	// The lease is renewed before it expires
+	time.Sleep(2 * testConfig.LeaseDuration)
+	if !e.IsLeader() {
+		t.Fatal("expected the controller to hold the leadership")
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("expected the election to stop once the context is done")
+	}
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "morty_controller"
+
+var (
+	// Invocations counts the function invocations, labelled by function name and HTTP status code
+	Invocations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "function_invocations_total",
+		Help:      "Total number of function invocations.",
+	}, []string{"function", "code"})
+
+	// InvocationDuration observes the end-to-end duration of the function invocations,
+	// including the time spent to start an instance if needed
+	InvocationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "function_invocation_duration_seconds",
+		Help:      "Duration of the function invocations in seconds.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"function"})
+
+	// ColdStarts counts the function instances created to serve an invocation
+	ColdStarts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "function_cold_starts_total",
+		Help:      "Total number of instances created to serve an invocation.",
+	}, []string{"function"})
+
+	// ScalingEvents counts the instances created or torn down by the autoscaler, labelled by direction (up or down)
+	ScalingEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "function_scaling_events_total",
+		Help:      "Total number of instances created or torn down by the autoscaler.",
+	}, []string{"function", "direction"})
+
+	// HealthcheckRetries counts the failed healthchecks performed against function instances
+	HealthcheckRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "function_healthcheck_retries_total",
+		Help:      "Total number of failed healthchecks performed against function instances.",
+	}, []string{"function"})
+
+	// ProxyErrors counts the invocations that couldn't be proxied to a function instance
+	ProxyErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "function_proxy_errors_total",
+		Help:      "Total number of errors encountered while proxying invocations to function instances.",
+	}, []string{"function"})
+
+	// Drift reports the drift found by the last reconciliation between the state and the orchestrator,
+	// labelled by kind (missing_functions, unprovisioned_functions, orphaned_instances or stale_instances)
+	Drift = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_drift",
+		Help:      "Number of differences found by the last reconciliation between the state and the orchestrator.",
+	}, []string{"kind"})
+
+	// Leader reports whether the controller is the leader handling the background tasks
+	Leader = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "leader",
+		Help:      "Whether the controller is the leader handling the background tasks (1) or not (0).",
+	})
+)
+
+// Handler returns an HTTP handler exposing the metrics in the Prometheus format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,75 @@
+package orchestration
+
+import (
+	"context"
+	"sort"
+
+	"github.com/morty-faas/controller/metrics"
+	"github.com/morty-faas/controller/types"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+)
+
+// GroupRevisions groups the revisions provisioned into the orchestrator by function name. Each revision is
+// given as a function pinned to it, as orchestrators deploy the revisions as dedicated resources. The current
+// revision of each function is the latest one.
+func GroupRevisions(revisions []*types.Function) []*types.Function {
+	var functions []*types.Function
+	byName := map[string]*types.Function{}
+	for _, rev := range revisions {
+		fn, exists := byName[rev.Name]
+		if !exists {
+			fn = &types.Function{Name: rev.Name}
+			byName[rev.Name] = fn
+			functions = append(functions, fn)
+		}
+
+		fn.Revisions = append(fn.Revisions, &types.FnRevision{
+			Number:   rev.Revision,
+			Id:       rev.Id,
+			ImageURL: rev.ImageURL,
+		})
+
+		if rev.Revision > fn.Revision {
+			fn.Id, fn.ImageURL, fn.Revision = rev.Id, rev.ImageURL, rev.Revision
+		}
+	}
+
+	for _, fn := range functions {
+		sort.Slice(fn.Revisions, func(i, j int) bool {
+			return fn.Revisions[i].Number < fn.Revisions[j].Number
+		})
+	}
+
+	return functions
+}
+
+// ColdStarts coalesces the concurrent instance creations of a same function revision,
+// so a burst of invocations on a cold function only spawns a single instance.
+// The zero value is ready to use.
+type ColdStarts[T any] struct {
+	group singleflight.Group
+}
+
+// Do deploys a new instance of the function, or waits for the deployment already in progress for the same
+// revision. It reports whether the instance is shared with other callers. The deployment is detached from
+// the context of the caller, as the concurrent callers waiting for it mustn't fail if it's cancelled, but it
+// is still traced within the span of the caller.
+func (c *ColdStarts[T]) Do(ctx context.Context, fn *types.Function, deploy func(context.Context) (T, error)) (T, bool, error) {
+	coldCtx := trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+	v, err, shared := c.group.Do(fn.Id, func() (interface{}, error) {
+		log.Debugf("Deploying new instance for function: %+v", fn)
+		instance, err := deploy(coldCtx)
+		if err != nil {
+			return nil, err
+		}
+		metrics.ColdStarts.WithLabelValues(fn.Name).Inc()
+		return instance, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, shared, err
+	}
+	return v.(T), shared, nil
+}
@@ -0,0 +1,123 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/morty-faas/controller/types"
+)
+
+func TestColdStartsCoalescesConcurrentDeployments(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"success", nil},
+		{"failure", errors.New("no capacity left")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const callers = 5
+
+			var coldStarts ColdStarts[string]
+			var deployments int32
+			started := make(chan struct{})
+			release := make(chan struct{})
+
+			fn := &types.Function{Id: "weatho-r1", Name: "weatho", Revision: 1}
+			deploy := func(ctx context.Context) (string, error) {
+				if atomic.AddInt32(&deployments, 1) == 1 {
+					close(started)
+				}
+				<-release
+				if tt.err != nil {
+					return "", tt.err
+				}
+				return "instance-1", nil
+			}
+
+			type result struct {
+				instance string
+				err      error
+			}
+			results := make(chan result, callers)
+
+			var wg sync.WaitGroup
+			call := func() {
+				defer wg.Done()
+				instance, _, err := coldStarts.Do(context.Background(), fn, deploy)
+				results <- result{instance, err}
+			}
+
+			wg.Add(1)
+			go call()
+			<-started
+			for i := 1; i < callers; i++ {
+				wg.Add(1)
+				go call()
+			}
+			// Leave the time to the other callers to join the deployment in progress
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+			close(results)
+
+			if got := atomic.LoadInt32(&deployments); got != 1 {
+				t.Fatalf("expected a single deployment, got %d", got)
+			}
+			for r := range results {
+				if !errors.Is(r.err, tt.err) {
+					t.Errorf("expected error %v, got %v", tt.err, r.err)
+				}
+				if tt.err == nil && r.instance != "instance-1" {
+					t.Errorf("expected instance-1, got %q", r.instance)
+				}
+			}
+		})
+	}
+}
+
+func TestColdStartsDoesNotCoalesceDifferentFunctions(t *testing.T) {
+	var coldStarts ColdStarts[string]
+	started := make(chan string, 2)
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"weatho-r1", "hello-r1"} {
+		fn := &types.Function{Id: id, Name: id}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			instance, shared, err := coldStarts.Do(context.Background(), fn, func(ctx context.Context) (string, error) {
+				started <- fn.Id
+				<-release
+				return fn.Id, nil
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if shared {
+				t.Errorf("expected the instance of %s not to be shared", fn.Id)
+			}
+			if instance != fn.Id {
+				t.Errorf("expected instance %s, got %s", fn.Id, instance)
+			}
+		}()
+	}
+
+	// Both deployments must be in progress at the same time
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("expected both functions to be deployed concurrently")
+		}
+	}
+	close(release)
+	wg.Wait()
+}
@@ -0,0 +1,380 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/types"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// defaultNamespace is the namespace of the functions when no namespace is configured
+	defaultNamespace = "default"
+	// defaultPort is the port of the function runtime when no port is configured
+	defaultPort = 8080
+	// defaultSchedulingTimeout is the scheduling timeout when no timeout is configured
+	defaultSchedulingTimeout = 30 * time.Second
+	// schedulingPollInterval is the interval at which a new pod is checked while waiting for its address
+	schedulingPollInterval = 250 * time.Millisecond
+)
+
+type adapter struct {
+	cfg        *Config
+	client     clientset.Interface
+	coldStarts orchestration.ColdStarts[*corev1.Pod]
+}
+
+type Config struct {
+	// Kubeconfig is the path of the kubeconfig file used to reach the cluster. If empty, the
+	// in-cluster configuration is used, or the default kubeconfig when running out of a cluster.
+	Kubeconfig string `yaml:"kubeconfig"`
+	// Context is the kubeconfig context to use, the current context is used if empty
+	Context string `yaml:"context"`
+	// Namespace is the namespace where the functions are deployed. Defaults to "default".
+	Namespace string `yaml:"namespace"`
+	// Port is the port the function runtime listens on inside the pods. Defaults to 8080.
+	Port int32 `yaml:"port"`
+	// SchedulingTimeout is the maximum duration to wait for a new pod to get an address. Defaults to 30s.
+	SchedulingTimeout time.Duration `yaml:"schedulingTimeout"`
+}
+
+var (
+	ErrInstanceNotScheduled = errors.New("the instance has been created but isn't scheduled yet")
+	ErrInvalidFunctionName  = errors.New("the function name can't be used as a Kubernetes resource name")
+)
+
+var _ orchestration.Orchestrator = (*adapter)(nil)
+
+var tracer = otel.Tracer("github.com/morty-faas/controller/orchestration/kubernetes")
+
+// NewOrchestrator initializes the Kubernetes orchestrator adapter.
+func NewOrchestrator(cfg *Config) (orchestration.Orchestrator, error) {
+	restConfig, err := loadRestConfig(cfg)
+	if err != nil {
+		log.Errorf("Failed to load Kubernetes client configuration: %v", err)
+		return nil, err
+	}
+
+	// Each call to the Kubernetes API is traced
+	restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return otelhttp.NewTransport(rt)
+	})
+
+	client, err := clientset.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Orchestrator engine 'kubernetes' successfully initialized")
+	return newAdapter(cfg, client), nil
+}
+
+// newAdapter initializes the adapter with the given client, so any implementation
+// of the Kubernetes API (e.g. the fake clientset) can be used.
+func newAdapter(cfg *Config, client clientset.Interface) *adapter {
+	if cfg.Namespace == "" {
+		cfg.Namespace = defaultNamespace
+	}
+	if cfg.Port == 0 {
+		cfg.Port = defaultPort
+	}
+	if cfg.SchedulingTimeout == 0 {
+		cfg.SchedulingTimeout = defaultSchedulingTimeout
+	}
+	return &adapter{cfg: cfg, client: client}
+}
+
+// loadRestConfig is a helper function to build the configuration of the Kubernetes client
+func loadRestConfig(cfg *Config) (*rest.Config, error) {
+	if cfg.Kubeconfig == "" && cfg.Context == "" {
+		restConfig, err := rest.InClusterConfig()
+		if err == nil {
+			return restConfig, nil
+		}
+		if !errors.Is(err, rest.ErrNotInCluster) {
+			return nil, err
+		}
+		log.Debug("Not running inside a Kubernetes cluster, falling back to the default kubeconfig")
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = cfg.Kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: cfg.Context}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+}
+
+func (a *adapter) GetFunctions(ctx context.Context) ([]*types.Function, error) {
+	services, err := a.client.CoreV1().Services(a.cfg.Namespace).List(ctx, metav1.ListOptions{LabelSelector: managedSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []*types.Function
+	for i := range services.Items {
+		if rev, ok := mapServiceToFn(&services.Items[i]); ok {
+			revisions = append(revisions, rev)
+		}
+	}
+
+	// Each revision of a function is deployed as a dedicated service
+	return orchestration.GroupRevisions(revisions), nil
+}
+
+func (a *adapter) CreateFunction(ctx context.Context, fn *types.Function) (*types.Function, error) {
+	// The function name is part of the resource names, so it must be a valid DNS label
+	if errs := validation.IsDNS1123Label(makeServiceName(fn)); len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFunctionName, strings.Join(errs, ", "))
+	}
+
+	svc, err := a.client.CoreV1().Services(a.cfg.Namespace).Create(ctx, mapFnToService(fn, a.cfg.Port), metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	fn.Deployed(svc.Name)
+	return fn, nil
+}
+
+func (a *adapter) UpdateFunction(ctx context.Context, fn *types.Function) (*types.Function, error) {
+	log.Debugf("Registering new service for function: %+v", fn)
+	fn, err := a.CreateFunction(ctx, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Services of the previous revisions are kept so they can still be
+	// invoked or rolled back to, but their pods are torn down.
+	for _, rev := range fn.History() {
+		if rev.Number == fn.Revision || rev.Id == "" {
+			continue
+		}
+		if err := a.drainRevisionPods(ctx, fn.AtRevision(rev.Number)); err != nil {
+			return nil, err
+		}
+	}
+
+	return fn, nil
+}
+
+func (a *adapter) GetFunctionInstance(ctx context.Context, fn *types.Function) (*types.FnInstance, error) {
+	ctx, span := tracer.Start(ctx, "kubernetes.GetFunctionInstance", trace.WithAttributes(attribute.String("kubernetes.service", fn.Id)))
+	defer span.End()
+
+	pods, err := a.getRevisionPods(ctx, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Bool("kubernetes.cold_start", len(pods) == 0))
+
+	if len(pods) == 0 {
+		pod, shared, err := a.coldStarts.Do(ctx, fn, func(ctx context.Context) (*corev1.Pod, error) {
+			return a.scaleUp(ctx, fn)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		span.SetAttributes(attribute.Bool("kubernetes.cold_start.shared", shared))
+		pods = []corev1.Pod{*pod}
+	}
+
+	log.Debugf("%d instance(s)", len(pods))
+
+	pod := pods[rand.Intn(len(pods))]
+	return mapPod(fn, &pod, a.cfg.Port), nil
+}
+
+func (a *adapter) GetFunctionInstances(ctx context.Context, fn *types.Function) ([]*types.FnInstance, error) {
+	var result []*types.FnInstance
+	for _, rev := range fn.History() {
+		if rev.Id == "" {
+			continue
+		}
+
+		pinned := fn.AtRevision(rev.Number)
+		pods, err := a.getRevisionPods(ctx, pinned)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range pods {
+			result = append(result, mapPod(pinned, &pods[i], a.cfg.Port))
+		}
+	}
+
+	return result, nil
+}
+
+func (a *adapter) CreateFunctionInstance(ctx context.Context, fn *types.Function) (*types.FnInstance, error) {
+	log.Debugf("Scaling up function: %+v", fn)
+	pod, err := a.scaleUp(ctx, fn)
+	if err != nil {
+		return nil, err
+	}
+	return mapPod(fn, pod, a.cfg.Port), nil
+}
+
+// scaleUp is a helper function that creates a new pod for the current revision
+// of the function, and waits for it to get an address.
+func (a *adapter) scaleUp(ctx context.Context, fn *types.Function) (*corev1.Pod, error) {
+	pods := a.client.CoreV1().Pods(a.cfg.Namespace)
+
+	svc, err := a.client.CoreV1().Services(a.cfg.Namespace).Get(ctx, fn.Id, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := pods.Create(ctx, makePod(fn, svc, a.cfg.Port), metav1.CreateOptions{})
+	if err != nil {
+		err := fmt.Errorf("Failed to create instance: %v", err)
+		log.Error(err)
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).AddEvent("Waiting for the instance to be scheduled")
+
+	pod, err := a.waitServing(ctx, created.Name)
+	if err == nil {
+		return pod, nil
+	}
+
+	// The pod is torn down so it doesn't run unnoticed, the failure is returned anyway
+	if err := a.deletePod(context.Background(), created.Name); err != nil {
+		log.Errorf("Failed to delete unscheduled instance %s: %v", created.Name, err)
+	}
+	return nil, err
+}
+
+// waitServing is a helper function to wait for the pod to get an address. An error ErrInstanceNotScheduled
+// is returned if it doesn't get one within the scheduling timeout.
+func (a *adapter) waitServing(ctx context.Context, name string) (*corev1.Pod, error) {
+	pollCtx, cancel := context.WithTimeout(ctx, a.cfg.SchedulingTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(schedulingPollInterval)
+	defer ticker.Stop()
+
+	for {
+		pod, err := a.client.CoreV1().Pods(a.cfg.Namespace).Get(pollCtx, name, metav1.GetOptions{})
+		switch {
+		case err == nil && pod.Status.Phase == corev1.PodFailed:
+			return nil, fmt.Errorf("pod %s failed: %s", pod.Name, pod.Status.Message)
+		case err == nil && isServing(pod):
+			return pod, nil
+		case err != nil && pollCtx.Err() == nil:
+			return nil, err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-pollCtx.Done():
+			// The caller gave up, or the pod didn't get an address in time
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, ErrInstanceNotScheduled
+		}
+	}
+}
+
+func (a *adapter) Ping(ctx context.Context) error {
+	// Listing a single service of the namespace checks both the
+	// reachability of the cluster and our permissions on it.
+	_, err := a.client.CoreV1().Services(a.cfg.Namespace).List(ctx, metav1.ListOptions{LabelSelector: managedSelector, Limit: 1})
+	return err
+}
+
+// getRevisionPods is a helper function to retrieve the serving pods of the current revision of the function
+func (a *adapter) getRevisionPods(ctx context.Context, fn *types.Function) ([]corev1.Pod, error) {
+	list, err := a.client.CoreV1().Pods(a.cfg.Namespace).List(ctx, metav1.ListOptions{LabelSelector: revisionSelector(fn)})
+	if err != nil {
+		return nil, err
+	}
+
+	pods := make([]corev1.Pod, 0, len(list.Items))
+	for i := range list.Items {
+		if isServing(&list.Items[i]) {
+			pods = append(pods, list.Items[i])
+		}
+	}
+	return pods, nil
+}
+
+func (a *adapter) DeleteFunctionInstance(ctx context.Context, ref *types.FnInstanceRef) error {
+	pod, err := a.client.CoreV1().Pods(a.cfg.Namespace).Get(ctx, ref.InstanceId, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return orchestration.ErrInstanceNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// Ensure we never tear down a pod of another revision, nor a pod we didn't create
+	if pod.Labels[managedByLabel] != managedByValue || !belongsTo(pod, ref.FunctionId) {
+		return orchestration.ErrInstanceNotFound
+	}
+
+	return a.deletePod(ctx, pod.Name)
+}
+
+func (a *adapter) DeleteFunction(ctx context.Context, fn *types.Function) error {
+	for _, rev := range fn.History() {
+		if rev.Id == "" {
+			continue
+		}
+		if err := a.drainRevisionPods(ctx, fn.AtRevision(rev.Number)); err != nil {
+			return err
+		}
+		err := a.client.CoreV1().Services(a.cfg.Namespace).Delete(ctx, rev.Id, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// drainRevisionPods is a helper function to delete all the pods of the current revision of the function.
+func (a *adapter) drainRevisionPods(ctx context.Context, fn *types.Function) error {
+	list, err := a.client.CoreV1().Pods(a.cfg.Namespace).List(ctx, metav1.ListOptions{LabelSelector: revisionSelector(fn)})
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Tearing down %d instance(s) of service '%s'", len(list.Items), fn.Id)
+
+	for _, pod := range list.Items {
+		if err := a.deletePod(ctx, pod.Name); err != nil {
+			return fmt.Errorf("failed to delete instance %s: %v", pod.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// deletePod is a helper function to delete a pod, ignoring the pods already deleted
+func (a *adapter) deletePod(ctx context.Context, name string) error {
+	err := a.client.CoreV1().Pods(a.cfg.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,250 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const (
+	testNamespace = "functions"
+	testPodIP     = "10.0.0.7"
+)
+
+// newTestAdapter initializes an adapter backed by a fake clientset. If schedule is true,
+// the created pods get an address right away, as if they were scheduled by the cluster.
+func newTestAdapter(t *testing.T, schedule bool, objects ...runtime.Object) (*adapter, *fake.Clientset) {
+	t.Helper()
+
+	client := fake.NewSimpleClientset(objects...)
+	if schedule {
+		client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+			pod.Status.Phase = corev1.PodRunning
+			pod.Status.PodIP = testPodIP
+			// The pod is stored by the next reactor
+			return false, nil, nil
+		})
+	}
+
+	cfg := &Config{Namespace: testNamespace, SchedulingTimeout: 100 * time.Millisecond}
+	return newAdapter(cfg, client), client
+}
+
+// deployFunction registers the first revision of a function into the fake cluster
+func deployFunction(t *testing.T, a *adapter, name string) *types.Function {
+	t.Helper()
+
+	fn, err := a.CreateFunction(context.Background(), (&types.Function{Name: name}).NewRevision("morty/"+name+":v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fn
+}
+
+// listPods returns the names of the pods of the namespace
+func listPods(t *testing.T, client *fake.Clientset) []string {
+	t.Helper()
+
+	list, err := client.CoreV1().Pods(testNamespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, pod := range list.Items {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func TestCreateFunction(t *testing.T) {
+	a, client := newTestAdapter(t, true)
+
+	fn := deployFunction(t, a, "weatho")
+	if fn.Id != "weatho-r1" {
+		t.Fatalf("CreateFunction() deployed %q, want weatho-r1", fn.Id)
+	}
+	if rev := fn.GetRevision(1); rev == nil || rev.Id != "weatho-r1" {
+		t.Fatalf("revision 1 = %+v, want it deployed as weatho-r1", rev)
+	}
+
+	svc, err := client.CoreV1().Services(testNamespace).Get(context.Background(), "weatho-r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.Labels[managedByLabel] != managedByValue || svc.Annotations[imageAnnotation] != "morty/weatho:v1" {
+		t.Fatalf("service = %+v, want it managed by morty and running morty/weatho:v1", svc.ObjectMeta)
+	}
+}
+
+func TestCreateFunctionInvalidName(t *testing.T) {
+	a, _ := newTestAdapter(t, true)
+
+	_, err := a.CreateFunction(context.Background(), (&types.Function{Name: "Weatho_App"}).NewRevision("morty/weatho:v1"))
+	if !errors.Is(err, ErrInvalidFunctionName) {
+		t.Fatalf("CreateFunction() = %v, want ErrInvalidFunctionName", err)
+	}
+}
+
+func TestGetFunctionInstanceColdStart(t *testing.T) {
+	a, client := newTestAdapter(t, true)
+	fn := deployFunction(t, a, "weatho")
+
+	instance, err := a.GetFunctionInstance(context.Background(), fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "http://" + testPodIP + ":8080"; instance.Endpoint.String() != want {
+		t.Fatalf("instance endpoint = %s, want %s", instance.Endpoint, want)
+	}
+
+	// The running pod is reused by the next invocations
+	if _, err := a.GetFunctionInstance(context.Background(), fn); err != nil {
+		t.Fatal(err)
+	}
+	if pods := listPods(t, client); len(pods) != 1 || pods[0] != instance.Id {
+		t.Fatalf("pods = %v, want only %s", pods, instance.Id)
+	}
+}
+
+func TestCreateFunctionInstanceNotScheduled(t *testing.T) {
+	a, client := newTestAdapter(t, false)
+	fn := deployFunction(t, a, "weatho")
+
+	_, err := a.CreateFunctionInstance(context.Background(), fn)
+	if !errors.Is(err, ErrInstanceNotScheduled) {
+		t.Fatalf("CreateFunctionInstance() = %v, want ErrInstanceNotScheduled", err)
+	}
+
+	// The unscheduled pod doesn't run unnoticed
+	if pods := listPods(t, client); len(pods) != 0 {
+		t.Fatalf("pods = %v, want none", pods)
+	}
+}
+
+func TestUpdateFunctionDrainsPreviousRevision(t *testing.T) {
+	a, client := newTestAdapter(t, true)
+	fn := deployFunction(t, a, "weatho")
+
+	previous, err := a.CreateFunctionInstance(context.Background(), fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := a.UpdateFunction(context.Background(), fn.NewRevision("morty/weatho:v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Id != "weatho-r2" {
+		t.Fatalf("UpdateFunction() deployed %q, want weatho-r2", next.Id)
+	}
+
+	if _, err := client.CoreV1().Pods(testNamespace).Get(context.Background(), previous.Id, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("pod of the previous revision = %v, want it deleted", err)
+	}
+
+	// The service of the previous revision is kept, so it can be rolled back to
+	if _, err := client.CoreV1().Services(testNamespace).Get(context.Background(), "weatho-r1", metav1.GetOptions{}); err != nil {
+		t.Fatalf("service of the previous revision = %v, want it kept", err)
+	}
+}
+
+func TestGetFunctionsGroupsRevisions(t *testing.T) {
+	unmanaged := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "database", Namespace: testNamespace}}
+	a, _ := newTestAdapter(t, true, unmanaged)
+
+	weatho := deployFunction(t, a, "weatho")
+	if _, err := a.UpdateFunction(context.Background(), weatho.NewRevision("morty/weatho:v2")); err != nil {
+		t.Fatal(err)
+	}
+	deployFunction(t, a, "hello")
+
+	functions, err := a.GetFunctions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := map[string]*types.Function{}
+	for _, fn := range functions {
+		byName[fn.Name] = fn
+	}
+	if len(byName) != 2 || byName["weatho"] == nil || byName["hello"] == nil {
+		t.Fatalf("GetFunctions() = %+v, want weatho and hello", functions)
+	}
+
+	fn := byName["weatho"]
+	if fn.Id != "weatho-r2" || fn.Revision != 2 || fn.ImageURL != "morty/weatho:v2" {
+		t.Fatalf("weatho = %+v, want its current revision to be weatho-r2", fn)
+	}
+	if len(fn.Revisions) != 2 || fn.Revisions[0].Id != "weatho-r1" || fn.Revisions[1].Id != "weatho-r2" {
+		t.Fatalf("weatho revisions = %+v, want weatho-r1 and weatho-r2", fn.Revisions)
+	}
+}
+
+func TestDeleteFunctionInstance(t *testing.T) {
+	a, client := newTestAdapter(t, true)
+	fn := deployFunction(t, a, "weatho")
+
+	instance, err := a.CreateFunctionInstance(context.Background(), fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.DeleteFunctionInstance(context.Background(), instance.Ref()); err != nil {
+		t.Fatal(err)
+	}
+	if pods := listPods(t, client); len(pods) != 0 {
+		t.Fatalf("pods = %v, want none", pods)
+	}
+
+	err = a.DeleteFunctionInstance(context.Background(), instance.Ref())
+	if !errors.Is(err, orchestration.ErrInstanceNotFound) {
+		t.Fatalf("DeleteFunctionInstance() of a deleted pod = %v, want ErrInstanceNotFound", err)
+	}
+}
+
+func TestDeleteFunctionInstanceRefusesForeignPods(t *testing.T) {
+	// The pod runs the function, but wasn't created by the controller
+	unmanaged := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "weatho-debug",
+		Namespace: testNamespace,
+		Labels:    map[string]string{functionLabel: "weatho", revisionLabel: "1"},
+	}}
+	a, client := newTestAdapter(t, true, unmanaged)
+
+	fn := deployFunction(t, a, "weatho")
+	next, err := a.UpdateFunction(context.Background(), fn.NewRevision("morty/weatho:v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance, err := a.CreateFunctionInstance(context.Background(), next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs := []*types.FnInstanceRef{
+		// The pod belongs to another revision of the function
+		{FunctionId: "weatho-r1", InstanceId: instance.Id},
+		{FunctionId: "weatho-r1", InstanceId: unmanaged.Name},
+	}
+	for _, ref := range refs {
+		if err := a.DeleteFunctionInstance(context.Background(), ref); !errors.Is(err, orchestration.ErrInstanceNotFound) {
+			t.Errorf("DeleteFunctionInstance(%s) = %v, want ErrInstanceNotFound", ref, err)
+		}
+	}
+
+	if pods := listPods(t, client); len(pods) != 2 {
+		t.Fatalf("pods = %v, want %s and %s to be kept", pods, instance.Id, unmanaged.Name)
+	}
+}
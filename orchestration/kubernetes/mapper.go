@@ -0,0 +1,151 @@
+package kubernetes
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/morty-faas/controller/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+const (
+	// revisionSuffix separates the function name from the revision number in the
+	// name of the services, e.g: `weatho-r2`
+	revisionSuffix = "-r"
+	// containerName is the name of the container running the function inside the pods
+	containerName = "function"
+
+	// podNameSuffixLength is the length of the random suffix of the pod names
+	podNameSuffixLength = 5
+
+	managedByLabel  = "app.kubernetes.io/managed-by"
+	managedByValue  = "morty"
+	functionLabel   = "morty.dev/function"
+	revisionLabel   = "morty.dev/revision"
+	imageAnnotation = "morty.dev/image"
+)
+
+// managedSelector selects all the resources created by the controller
+var managedSelector = labels.SelectorFromSet(labels.Set{managedByLabel: managedByValue}).String()
+
+// makeServiceName is a helper function that computes the name of the service
+// associated to the current revision of the function, e.g: `weatho-r2`
+func makeServiceName(fn *types.Function) string {
+	return fmt.Sprintf("%s%s%d", fn.Name, revisionSuffix, fn.Revision)
+}
+
+// revisionLabels is a helper function that computes the labels shared by the service
+// of the current revision of the function and its pods.
+func revisionLabels(fn *types.Function) labels.Set {
+	return labels.Set{
+		managedByLabel: managedByValue,
+		functionLabel:  fn.Name,
+		revisionLabel:  strconv.Itoa(fn.Revision),
+	}
+}
+
+// revisionSelector is a helper function that selects the pods of the current revision of the function
+func revisionSelector(fn *types.Function) string {
+	return labels.SelectorFromSet(revisionLabels(fn)).String()
+}
+
+// mapFnToService is a helper function that maps the current revision of a Morty function to a
+// headless Kubernetes service. The service holds the definition of the revision, and selects its pods.
+func mapFnToService(fn *types.Function, port int32) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        makeServiceName(fn),
+			Labels:      revisionLabels(fn),
+			Annotations: map[string]string{imageAnnotation: fn.ImageURL},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  revisionLabels(fn),
+			Ports: []corev1.ServicePort{{
+				Name:       "http",
+				Port:       port,
+				TargetPort: intstr.FromInt(int(port)),
+			}},
+		},
+	}
+}
+
+// mapServiceToFn is a helper function that maps a Kubernetes service to a Morty function.
+// It returns false if the service doesn't describe a function revision.
+func mapServiceToFn(svc *corev1.Service) (*types.Function, bool) {
+	name := svc.Labels[functionLabel]
+	revision, err := strconv.Atoi(svc.Labels[revisionLabel])
+	if name == "" || err != nil {
+		return nil, false
+	}
+
+	return &types.Function{
+		Id:       svc.Name,
+		Name:     name,
+		ImageURL: svc.Annotations[imageAnnotation],
+		Revision: revision,
+	}, true
+}
+
+// makePod is a helper function that computes a new pod running the current revision of the function.
+// The pod is owned by the service of the revision, so it is garbage collected along with it.
+func makePod(fn *types.Function, svc *corev1.Service, port int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			// The name is generated by us rather than the API server, so it is known before the creation
+			Name:   fmt.Sprintf("%s-%s", svc.Name, utilrand.String(podNameSuffixLength)),
+			Labels: revisionLabels(fn),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "Service",
+				Name:       svc.Name,
+				UID:        svc.UID,
+			}},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  containerName,
+				Image: fn.ImageURL,
+				Ports: []corev1.ContainerPort{{
+					Name:          "http",
+					ContainerPort: port,
+				}},
+			}},
+		},
+	}
+}
+
+// isServing is a helper function that returns true if the pod has an address and isn't terminated nor terminating
+func isServing(pod *corev1.Pod) bool {
+	return pod.Status.PodIP != "" &&
+		pod.DeletionTimestamp == nil &&
+		pod.Status.Phase != corev1.PodSucceeded &&
+		pod.Status.Phase != corev1.PodFailed
+}
+
+// belongsTo is a helper function that returns true if the pod runs the revision of the given service
+func belongsTo(pod *corev1.Pod, serviceName string) bool {
+	fn := &types.Function{Name: pod.Labels[functionLabel]}
+	revision, err := strconv.Atoi(pod.Labels[revisionLabel])
+	if fn.Name == "" || err != nil {
+		return false
+	}
+	fn.Revision = revision
+	return makeServiceName(fn) == serviceName
+}
+
+// mapPod is a helper function that maps a Kubernetes pod to a Morty function instance.
+// The instance endpoint is the address of the pod, as the controller reaches each instance directly.
+func mapPod(fn *types.Function, pod *corev1.Pod, port int32) *types.FnInstance {
+	return &types.FnInstance{
+		Id:       pod.Name,
+		Function: fn,
+		Endpoint: &url.URL{Scheme: "http", Host: net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port)))},
+	}
+}
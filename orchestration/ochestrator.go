@@ -2,10 +2,15 @@ package orchestration
 
 import (
 	"context"
+	"errors"
 
 	"github.com/morty-faas/controller/types"
 )
 
+var (
+	ErrInstanceNotFound = errors.New("function instance not found")
+)
+
 type Orchestrator interface {
 	// GetFunctions retrieve all the functions currently provisioned into the orchestrator.
 	GetFunctions(ctx context.Context) ([]*types.Function, error)
@@ -13,9 +18,26 @@ type Orchestrator interface {
 	// CreateFunction register the function into the orchestrator, but doesn't deploy an instance of it.
 	CreateFunction(ctx context.Context, fn *types.Function) (*types.Function, error)
 
+	// UpdateFunction replace the definition of an existing function into the orchestrator.
+	// Instances running the previous definition of the function are torn down.
+	UpdateFunction(ctx context.Context, fn *types.Function) (*types.Function, error)
+
 	// GetFunctionInstance retrieve an instance of the function, that must be ready to receive requests.
 	GetFunctionInstance(ctx context.Context, fn *types.Function) (*types.FnInstance, error)
 
-	// DeleteFunctionInstance delete a function instance.
-	DeleteFunctionInstance(ctx context.Context, fn *types.Function) error
+	// GetFunctionInstances retrieve all the running instances of the function, across all of its revisions.
+	GetFunctionInstances(ctx context.Context, fn *types.Function) ([]*types.FnInstance, error)
+
+	// CreateFunctionInstance deploys an additional instance of the current revision of the function.
+	CreateFunctionInstance(ctx context.Context, fn *types.Function) (*types.FnInstance, error)
+
+	// DeleteFunctionInstance tears down the referenced instance. If the instance doesn't
+	// belong to the referenced function, an error ErrInstanceNotFound will be returned.
+	DeleteFunctionInstance(ctx context.Context, ref *types.FnInstanceRef) error
+
+	// DeleteFunction tears down every running instance of the function and unregister it from the orchestrator.
+	DeleteFunction(ctx context.Context, fn *types.Function) error
+
+	// Ping checks that the orchestrator is reachable.
+	Ping(ctx context.Context) error
 }
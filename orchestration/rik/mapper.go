@@ -1,26 +1,35 @@
 package rik
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/morty-faas/controller/types"
 	rik "github.com/rik-org/rik-go-client"
 )
 
+// revisionSeparator separates the function name from the revision number in the workload name.
+const revisionSeparator = "@"
+
 // mapRegisteredWorkloadToFn is a helper function that maps a RIK Workload to a Morty function
 func mapRegisteredWorkloadToFn(wk *rik.GetWorkloadsResponseInner) *types.Function {
+	name, revision := parseWorkloadName(wk.GetName())
 	return &types.Function{
 		Id:       wk.GetId(),
-		Name:     wk.GetName(),
+		Name:     name,
 		ImageURL: *wk.GetValue().Spec.Function.Execution.Rootfs,
+		Revision: revision,
 	}
 }
 
 // mapFnToWorkload is a helper function that maps a Morty function to a RIK Workload
 func mapFnToWorkload(fn *types.Function) *rik.Workload {
-	apiVersion, kind := "v0", rik.KIND_FUNCTION
+	apiVersion, kind, name := "v0", rik.KIND_FUNCTION, makeWorkloadName(fn)
 	return &rik.Workload{
 		ApiVersion: &apiVersion,
 		Kind:       &kind,
-		Name:       &fn.Name,
+		Name:       &name,
 		Spec: &rik.WorkloadSpec{
 			Function: &rik.Function{
 				Execution: &rik.FunctionExecution{
@@ -30,3 +39,26 @@ func mapFnToWorkload(fn *types.Function) *rik.Workload {
 		},
 	}
 }
+
+// makeWorkloadName is a helper function that computes the name of the workload
+// associated to the current revision of the function, e.g: `weatho@2`
+func makeWorkloadName(fn *types.Function) string {
+	return fmt.Sprintf("%s%s%d", fn.Name, revisionSeparator, fn.Revision)
+}
+
+// parseWorkloadName is a helper function that extracts the function name and the revision
+// number from a workload name. Workloads registered before revisions were introduced
+// don't have a revision suffix, so they are considered as the first revision.
+func parseWorkloadName(workloadName string) (string, int) {
+	i := strings.LastIndex(workloadName, revisionSeparator)
+	if i == -1 {
+		return workloadName, 1
+	}
+
+	revision, err := strconv.Atoi(workloadName[i+1:])
+	if err != nil {
+		return workloadName, 1
+	}
+
+	return workloadName[:i], revision
+}
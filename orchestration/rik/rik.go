@@ -13,22 +13,40 @@ import (
 	"github.com/morty-faas/controller/types"
 	rik "github.com/rik-org/rik-go-client"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type adapter struct {
-	cfg    *Config
-	client *rik.APIClient
+	cfg        *Config
+	client     *rik.APIClient
+	coldStarts orchestration.ColdStarts[*rik.Instance]
 }
 
 type Config struct {
-	// Cluster is the address of the RIK controller
+	// Cluster is the address of the RIK controller. Defaults to "http://localhost:5000".
 	Cluster string `yaml:"cluster"`
 }
 
+// defaultCluster is the address of the RIK controller when no address is configured
+const defaultCluster = "http://localhost:5000"
+
+var (
+	ErrInstanceNotScheduled = errors.New("the instance has been created but isn't scheduled yet")
+)
+
 var _ orchestration.Orchestrator = (*adapter)(nil)
 
+var tracer = otel.Tracer("github.com/morty-faas/controller/orchestration/rik")
+
 // NewOrchestrator initializes the RIK orchestrator adapter.
 func NewOrchestrator(cfg *Config) (orchestration.Orchestrator, error) {
+	if cfg.Cluster == "" {
+		cfg.Cluster = defaultCluster
+	}
+
 	log.Info("Orchestrator engine 'rik' successfully initialized")
 
 	client := rik.NewAPIClient(&rik.Configuration{
@@ -37,9 +55,13 @@ func NewOrchestrator(cfg *Config) (orchestration.Orchestrator, error) {
 				URL: cfg.Cluster,
 			},
 		},
+		// Each call to the RIK cluster is traced
+		HTTPClient: &http.Client{
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
 	})
 
-	return &adapter{cfg, client}, nil
+	return &adapter{cfg: cfg, client: client}, nil
 }
 
 func (a *adapter) GetFunctions(ctx context.Context) ([]*types.Function, error) {
@@ -48,20 +70,18 @@ func (a *adapter) GetFunctions(ctx context.Context) ([]*types.Function, error) {
 		return nil, err
 	}
 
-	var functions []*types.Function
-	for _, meta := range workloads {
-		workload := meta.GetValue()
+	var revisions []*types.Function
+	for i := range workloads {
 		// Filter on function elements only
-		if workload.GetKind() == rik.KIND_FUNCTION {
-			functions = append(functions, &types.Function{
-				Id:       meta.GetId(),
-				Name:     workload.GetName(),
-				ImageURL: *workload.GetSpec().Function.Execution.Rootfs,
-			})
+		workload := workloads[i].GetValue()
+		if workload.GetKind() != rik.KIND_FUNCTION {
+			continue
 		}
+		revisions = append(revisions, mapRegisteredWorkloadToFn(&workloads[i]))
 	}
 
-	return functions, nil
+	// Each revision of a function is registered as a dedicated workload
+	return orchestration.GroupRevisions(revisions), nil
 }
 
 func (a *adapter) CreateFunction(ctx context.Context, fn *types.Function) (*types.Function, error) {
@@ -71,47 +91,145 @@ func (a *adapter) CreateFunction(ctx context.Context, fn *types.Function) (*type
 		return nil, err
 	}
 
-	fn.Id = wk.CreateWorkloadResponse.GetId()
+	fn.Deployed(wk.CreateWorkloadResponse.GetId())
+	return fn, nil
+}
+
+func (a *adapter) UpdateFunction(ctx context.Context, fn *types.Function) (*types.Function, error) {
+	log.Debugf("Registering new workload for function: %+v", fn)
+	fn, err := a.CreateFunction(ctx, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Workloads of the previous revisions are kept so they can still be
+	// invoked or rolled back to, but their instances are torn down.
+	for _, rev := range fn.History() {
+		if rev.Number == fn.Revision || rev.Id == "" {
+			continue
+		}
+		if err := a.drainWorkloadInstances(ctx, rev.Id); err != nil {
+			return nil, err
+		}
+	}
+
 	return fn, nil
 }
 
 func (a *adapter) GetFunctionInstance(ctx context.Context, fn *types.Function) (*types.FnInstance, error) {
+	ctx, span := tracer.Start(ctx, "rik.GetFunctionInstance", trace.WithAttributes(attribute.String("rik.workload.id", fn.Id)))
+	defer span.End()
+
 	instances, err := a.getWorkloadInstances(ctx, fn.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(instances) == 0 {
-		log.Debugf("Deploying new instance for function: %+v", fn)
+	span.SetAttributes(attribute.Bool("rik.cold_start", len(instances) == 0))
 
-		if err := a.createWorkloadInstance(ctx, fn.Id, fn.Name); err != nil {
-			err := fmt.Errorf("Failed to create instance: %v", err)
-			log.Error(err)
+	if len(instances) == 0 {
+		instance, shared, err := a.coldStarts.Do(ctx, fn, func(ctx context.Context) (*rik.Instance, error) {
+			return a.scaleUp(ctx, fn)
+		})
+		if err != nil {
 			return nil, err
 		}
 
-		time.Sleep(500 * time.Millisecond)
+		span.SetAttributes(attribute.Bool("rik.cold_start.shared", shared))
+		instances = []rik.Instance{*instance}
+	}
 
-		instances, err = a.getWorkloadInstances(ctx, fn.Id)
+	log.Debugf("%d instance(s)", len(instances))
+
+	rikIn := instances[rand.Intn(len(instances))]
+	return a.mapInstance(fn, &rikIn), nil
+}
+
+func (a *adapter) GetFunctionInstances(ctx context.Context, fn *types.Function) ([]*types.FnInstance, error) {
+	var result []*types.FnInstance
+	for _, rev := range fn.History() {
+		if rev.Id == "" {
+			continue
+		}
+
+		instances, err := a.getWorkloadInstances(ctx, rev.Id)
 		if err != nil {
 			return nil, err
 		}
+
+		pinned := fn.AtRevision(rev.Number)
+		for i := range instances {
+			result = append(result, a.mapInstance(pinned, &instances[i]))
+		}
 	}
 
-	log.Debugf("%d instance(s)", len(instances))
+	return result, nil
+}
 
-	rikIn := instances[rand.Intn(len(instances))]
+func (a *adapter) CreateFunctionInstance(ctx context.Context, fn *types.Function) (*types.FnInstance, error) {
+	log.Debugf("Scaling up function: %+v", fn)
+	instance, err := a.scaleUp(ctx, fn)
+	if err != nil {
+		return nil, err
+	}
+	return a.mapInstance(fn, instance), nil
+}
 
+// scaleUp is a helper function that creates a new instance of the function
+// and waits for it to be scheduled by the RIK cluster.
+func (a *adapter) scaleUp(ctx context.Context, fn *types.Function) (*rik.Instance, error) {
+	// RIK doesn't tell us which instance has been created, so we look
+	// for an instance that wasn't existing before the creation.
+	existing, err := a.getWorkloadInstances(ctx, fn.Id)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, instance := range existing {
+		known[instance.GetId()] = true
+	}
+
+	if err := a.createWorkloadInstance(ctx, fn.Id, fn.Name); err != nil {
+		err := fmt.Errorf("Failed to create instance: %v", err)
+		log.Error(err)
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).AddEvent("Waiting for the instance to be scheduled")
+	time.Sleep(500 * time.Millisecond)
+
+	instances, err := a.getWorkloadInstances(ctx, fn.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range instances {
+		if !known[instances[i].GetId()] {
+			return &instances[i], nil
+		}
+	}
+
+	return nil, ErrInstanceNotScheduled
+}
+
+// mapInstance is a helper function that maps a RIK instance to a Morty function instance.
+// The instance endpoint is exposed on the host of the RIK cluster.
+func (a *adapter) mapInstance(fn *types.Function, rikIn *rik.Instance) *types.FnInstance {
 	url, _ := url.Parse(a.cfg.Cluster)
 	url, _ = url.Parse(fmt.Sprintf("%s://%s:%d", url.Scheme, url.Hostname(), rikIn.Spec.Function.Exposure.GetPort()))
 
-	instance := &types.FnInstance{
+	return &types.FnInstance{
 		Id:       rikIn.GetId(),
 		Function: fn,
 		Endpoint: url,
 	}
+}
 
-	return instance, nil
+func (a *adapter) Ping(ctx context.Context) error {
+	// RIK doesn't expose a dedicated health endpoint, listing the
+	// workloads is the cheapest call we can make to the cluster.
+	_, err := a.getWorkloads(ctx)
+	return err
 }
 
 // getWorkloads is a helper function to retrieve all the workloads from the RIK cluster
@@ -154,11 +272,71 @@ func (a *adapter) createWorkloadInstance(ctx context.Context, workloadId, name s
 	return nil
 }
 
-func (a *adapter) DeleteFunctionInstance(ctx context.Context, fn *types.Function) error {
+func (a *adapter) DeleteFunctionInstance(ctx context.Context, ref *types.FnInstanceRef) error {
+	instances, err := a.getWorkloadInstances(ctx, ref.FunctionId)
+	if err != nil {
+		return err
+	}
+
+	// Ensure we never tear down an instance of another workload
+	for _, instance := range instances {
+		if instance.GetId() == ref.InstanceId {
+			return a.deleteWorkloadInstance(ctx, ref.InstanceId)
+		}
+	}
+
+	return orchestration.ErrInstanceNotFound
+}
+
+func (a *adapter) DeleteFunction(ctx context.Context, fn *types.Function) error {
+	for _, rev := range fn.History() {
+		if rev.Id == "" {
+			continue
+		}
+		if err := a.drainWorkloadInstances(ctx, rev.Id); err != nil {
+			return err
+		}
+		if err := a.deleteWorkload(ctx, rev.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drainWorkloadInstances is a helper function to delete all the instances of the given workload.
+func (a *adapter) drainWorkloadInstances(ctx context.Context, workloadId string) error {
+	instances, err := a.getWorkloadInstances(ctx, workloadId)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Tearing down %d instance(s) of workload '%s'", len(instances), workloadId)
+
+	for _, instance := range instances {
+		if err := a.deleteWorkloadInstance(ctx, instance.GetId()); err != nil {
+			return fmt.Errorf("failed to delete instance %s: %v", instance.GetId(), err)
+		}
+	}
+
+	return nil
+}
+
+// deleteWorkloadInstance is a helper function to delete a workload instance
+func (a *adapter) deleteWorkloadInstance(ctx context.Context, id string) error {
 	input := rik.DeleteInstanceRequest{
-		Id: &fn.Name,
+		Id: &id,
 	}
 
 	_, err := a.client.InstancesApi.DeleteInstance(ctx).DeleteInstanceRequest(input).Execute()
 	return err
 }
+
+// deleteWorkload is a helper function to unregister a workload from the RIK cluster
+func (a *adapter) deleteWorkload(ctx context.Context, id string) error {
+	metadata := rik.Metadata{
+		Id: &id,
+	}
+
+	_, err := a.client.WorkloadsApi.DeleteWorkload(ctx).Metadata(metadata).Execute()
+	return err
+}
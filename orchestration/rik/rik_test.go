@@ -0,0 +1,100 @@
+package rik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/types"
+)
+
+// fakeCluster emulates the instances API of a RIK cluster
+type fakeCluster struct {
+	mu sync.Mutex
+	// instances contains the instance identifiers, indexed by workload identifier
+	instances map[string][]string
+	deleted   []string
+}
+
+func (f *fakeCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/v0/workloads.instances/"):
+		workloadId := strings.TrimPrefix(r.URL.Path, "/api/v0/workloads.instances/")
+		instances := []map[string]any{}
+		for _, id := range f.instances[workloadId] {
+			instances = append(instances, map[string]any{"id": id, "workload_id": workloadId})
+		}
+		writeJSON(w, map[string]any{"instances": instances})
+	case r.URL.Path == "/api/v0/instances.delete":
+		var body struct {
+			Id string `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.deleted = append(f.deleted, body.Id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func newTestOrchestrator(t *testing.T, cluster *fakeCluster) orchestration.Orchestrator {
+	t.Helper()
+
+	srv := httptest.NewServer(cluster)
+	t.Cleanup(srv.Close)
+
+	orch, err := NewOrchestrator(&Config{Cluster: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return orch
+}
+
+func TestDeleteFunctionInstance(t *testing.T) {
+	cluster := &fakeCluster{instances: map[string][]string{"workload-1": {"instance-1"}}}
+	orch := newTestOrchestrator(t, cluster)
+
+	err := orch.DeleteFunctionInstance(context.Background(), &types.FnInstanceRef{FunctionId: "workload-1", InstanceId: "instance-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cluster.deleted) != 1 || cluster.deleted[0] != "instance-1" {
+		t.Fatalf("deleted instances = %v, want [instance-1]", cluster.deleted)
+	}
+}
+
+func TestDeleteFunctionInstanceOfAnotherWorkload(t *testing.T) {
+	cluster := &fakeCluster{instances: map[string][]string{
+		"workload-1": {"instance-1"},
+		"workload-2": {"instance-2"},
+	}}
+	orch := newTestOrchestrator(t, cluster)
+
+	// The instance exists, but it doesn't belong to the referenced workload
+	err := orch.DeleteFunctionInstance(context.Background(), &types.FnInstanceRef{FunctionId: "workload-1", InstanceId: "instance-2"})
+	if !errors.Is(err, orchestration.ErrInstanceNotFound) {
+		t.Fatalf("DeleteFunctionInstance() = %v, want ErrInstanceNotFound", err)
+	}
+
+	if len(cluster.deleted) != 0 {
+		t.Fatalf("deleted instances = %v, want none", cluster.deleted)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 
@@ -35,6 +36,50 @@ type FunctionApi interface {
 	//  @return Function
 	CreateFunctionExecute(r FunctionApiCreateFunctionRequest) (*Function, *http.Response, error)
 
+	/*
+	DeleteFunction Delete a function
+
+	Delete a function and tear down all of its running instances.
+
+	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	@param name The name of the function
+	@return FunctionApiDeleteFunctionRequest
+	*/
+	DeleteFunction(ctx context.Context, name string) FunctionApiDeleteFunctionRequest
+
+	// DeleteFunctionExecute executes the request
+	DeleteFunctionExecute(r FunctionApiDeleteFunctionRequest) (*http.Response, error)
+
+	/*
+	DeleteFunctionAlias Delete a function alias
+
+	Delete an alias of the function. The revisions targeted by the alias are left untouched.
+
+	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	@param name The name of the function
+	@param alias The name of the alias
+	@return FunctionApiDeleteFunctionAliasRequest
+	*/
+	DeleteFunctionAlias(ctx context.Context, name string, alias string) FunctionApiDeleteFunctionAliasRequest
+
+	// DeleteFunctionAliasExecute executes the request
+	DeleteFunctionAliasExecute(r FunctionApiDeleteFunctionAliasRequest) (*http.Response, error)
+
+	/*
+	GetFunction Get a function
+
+	Get the definition of a function along with its running instances.
+
+	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	@param name The name of the function
+	@return FunctionApiGetFunctionRequest
+	*/
+	GetFunction(ctx context.Context, name string) FunctionApiGetFunctionRequest
+
+	// GetFunctionExecute executes the request
+	//  @return FunctionDetails
+	GetFunctionExecute(r FunctionApiGetFunctionRequest) (*FunctionDetails, *http.Response, error)
+
 	/*
 	GetFunctions Get a list of the available functions
 
@@ -48,6 +93,52 @@ type FunctionApi interface {
 	// GetFunctionsExecute executes the request
 	//  @return []Function
 	GetFunctionsExecute(r FunctionApiGetFunctionsRequest) ([]Function, *http.Response, error)
+
+	/*
+	PutFunctionAlias Create or update a function alias
+
+	Create or update an alias splitting the invocations of the function between its revisions. The alias can be invoked using `/functions/{name}@{alias}/invoke`.
+
+	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	@param name The name of the function
+	@param alias The name of the alias
+	@return FunctionApiPutFunctionAliasRequest
+	*/
+	PutFunctionAlias(ctx context.Context, name string, alias string) FunctionApiPutFunctionAliasRequest
+
+	// PutFunctionAliasExecute executes the request
+	//  @return Function
+	PutFunctionAliasExecute(r FunctionApiPutFunctionAliasRequest) (*Function, *http.Response, error)
+
+	/*
+	RollbackFunction Rollback a function
+
+	Rollback a function to a previous revision. As revisions are immutable, a new revision is created with the definition of the targeted revision.
+
+	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	@param name The name of the function
+	@return FunctionApiRollbackFunctionRequest
+	*/
+	RollbackFunction(ctx context.Context, name string) FunctionApiRollbackFunctionRequest
+
+	// RollbackFunctionExecute executes the request
+	//  @return Function
+	RollbackFunctionExecute(r FunctionApiRollbackFunctionRequest) (*Function, *http.Response, error)
+
+	/*
+	UpdateFunction Update a function
+
+	Update the image of an existing function. Instances running the previous image are torn down.
+
+	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	@param name The name of the function
+	@return FunctionApiUpdateFunctionRequest
+	*/
+	UpdateFunction(ctx context.Context, name string) FunctionApiUpdateFunctionRequest
+
+	// UpdateFunctionExecute executes the request
+	//  @return Function
+	UpdateFunctionExecute(r FunctionApiUpdateFunctionRequest) (*Function, *http.Response, error)
 }
 
 // FunctionApiService FunctionApi service
@@ -195,46 +286,280 @@ func (a *FunctionApiService) CreateFunctionExecute(r FunctionApiCreateFunctionRe
 	return localVarReturnValue, localVarHTTPResponse, nil
 }
 
-type FunctionApiGetFunctionsRequest struct {
+type FunctionApiDeleteFunctionRequest struct {
 	ctx context.Context
 	ApiService FunctionApi
+	name string
 }
 
-func (r FunctionApiGetFunctionsRequest) Execute() ([]Function, *http.Response, error) {
-	return r.ApiService.GetFunctionsExecute(r)
+func (r FunctionApiDeleteFunctionRequest) Execute() (*http.Response, error) {
+	return r.ApiService.DeleteFunctionExecute(r)
 }
 
 /*
-GetFunctions Get a list of the available functions
+DeleteFunction Delete a function
 
-Get a list of the available functions
+Delete a function and tear down all of its running instances.
 
  @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
- @return FunctionApiGetFunctionsRequest
+ @param name The name of the function
+ @return FunctionApiDeleteFunctionRequest
 */
-func (a *FunctionApiService) GetFunctions(ctx context.Context) FunctionApiGetFunctionsRequest {
-	return FunctionApiGetFunctionsRequest{
+func (a *FunctionApiService) DeleteFunction(ctx context.Context, name string) FunctionApiDeleteFunctionRequest {
+	return FunctionApiDeleteFunctionRequest{
 		ApiService: a,
 		ctx: ctx,
+		name: name,
 	}
 }
 
 // Execute executes the request
-//  @return []Function
-func (a *FunctionApiService) GetFunctionsExecute(r FunctionApiGetFunctionsRequest) ([]Function, *http.Response, error) {
+func (a *FunctionApiService) DeleteFunctionExecute(r FunctionApiDeleteFunctionRequest) (*http.Response, error) {
+	var (
+		localVarHTTPMethod   = http.MethodDelete
+		localVarPostBody     interface{}
+		formFiles            []formFile
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "FunctionApiService.DeleteFunction")
+	if err != nil {
+		return nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/functions/{name}"
+	localVarPath = strings.Replace(localVarPath, "{"+"name"+"}", url.PathEscape(parameterValueToString(r.name, "name")), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 404 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+			return localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 500 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+		}
+		return localVarHTTPResponse, newErr
+	}
+
+	return localVarHTTPResponse, nil
+}
+
+type FunctionApiDeleteFunctionAliasRequest struct {
+	ctx context.Context
+	ApiService FunctionApi
+	name string
+	alias string
+}
+
+func (r FunctionApiDeleteFunctionAliasRequest) Execute() (*http.Response, error) {
+	return r.ApiService.DeleteFunctionAliasExecute(r)
+}
+
+/*
+DeleteFunctionAlias Delete a function alias
+
+Delete an alias of the function. The revisions targeted by the alias are left untouched.
+
+ @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ @param name The name of the function
+ @param alias The name of the alias
+ @return FunctionApiDeleteFunctionAliasRequest
+*/
+func (a *FunctionApiService) DeleteFunctionAlias(ctx context.Context, name string, alias string) FunctionApiDeleteFunctionAliasRequest {
+	return FunctionApiDeleteFunctionAliasRequest{
+		ApiService: a,
+		ctx: ctx,
+		name: name,
+		alias: alias,
+	}
+}
+
+// Execute executes the request
+func (a *FunctionApiService) DeleteFunctionAliasExecute(r FunctionApiDeleteFunctionAliasRequest) (*http.Response, error) {
+	var (
+		localVarHTTPMethod   = http.MethodDelete
+		localVarPostBody     interface{}
+		formFiles            []formFile
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "FunctionApiService.DeleteFunctionAlias")
+	if err != nil {
+		return nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/functions/{name}/aliases/{alias}"
+	localVarPath = strings.Replace(localVarPath, "{"+"name"+"}", url.PathEscape(parameterValueToString(r.name, "name")), -1)
+	localVarPath = strings.Replace(localVarPath, "{"+"alias"+"}", url.PathEscape(parameterValueToString(r.alias, "alias")), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 404 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+			return localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 500 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+		}
+		return localVarHTTPResponse, newErr
+	}
+
+	return localVarHTTPResponse, nil
+}
+
+type FunctionApiGetFunctionRequest struct {
+	ctx context.Context
+	ApiService FunctionApi
+	name string
+}
+
+func (r FunctionApiGetFunctionRequest) Execute() (*FunctionDetails, *http.Response, error) {
+	return r.ApiService.GetFunctionExecute(r)
+}
+
+/*
+GetFunction Get a function
+
+Get the definition of a function along with its running instances.
+
+ @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ @param name The name of the function
+ @return FunctionApiGetFunctionRequest
+*/
+func (a *FunctionApiService) GetFunction(ctx context.Context, name string) FunctionApiGetFunctionRequest {
+	return FunctionApiGetFunctionRequest{
+		ApiService: a,
+		ctx: ctx,
+		name: name,
+	}
+}
+
+// Execute executes the request
+//  @return FunctionDetails
+func (a *FunctionApiService) GetFunctionExecute(r FunctionApiGetFunctionRequest) (*FunctionDetails, *http.Response, error) {
 	var (
 		localVarHTTPMethod   = http.MethodGet
 		localVarPostBody     interface{}
 		formFiles            []formFile
-		localVarReturnValue  []Function
+		localVarReturnValue  *FunctionDetails
 	)
 
-	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "FunctionApiService.GetFunctions")
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "FunctionApiService.GetFunction")
 	if err != nil {
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/functions"
+	localVarPath := localBasePath + "/functions/{name}"
+	localVarPath = strings.Replace(localVarPath, "{"+"name"+"}", url.PathEscape(parameterValueToString(r.name, "name")), -1)
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
@@ -279,6 +604,568 @@ func (a *FunctionApiService) GetFunctionsExecute(r FunctionApiGetFunctionsReques
 			body:  localVarBody,
 			error: localVarHTTPResponse.Status,
 		}
+		if localVarHTTPResponse.StatusCode == 404 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 500 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
+type FunctionApiGetFunctionsRequest struct {
+	ctx context.Context
+	ApiService FunctionApi
+}
+
+func (r FunctionApiGetFunctionsRequest) Execute() ([]Function, *http.Response, error) {
+	return r.ApiService.GetFunctionsExecute(r)
+}
+
+/*
+GetFunctions Get a list of the available functions
+
+Get a list of the available functions
+
+ @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ @return FunctionApiGetFunctionsRequest
+*/
+func (a *FunctionApiService) GetFunctions(ctx context.Context) FunctionApiGetFunctionsRequest {
+	return FunctionApiGetFunctionsRequest{
+		ApiService: a,
+		ctx: ctx,
+	}
+}
+
+// Execute executes the request
+//  @return []Function
+func (a *FunctionApiService) GetFunctionsExecute(r FunctionApiGetFunctionsRequest) ([]Function, *http.Response, error) {
+	var (
+		localVarHTTPMethod   = http.MethodGet
+		localVarPostBody     interface{}
+		formFiles            []formFile
+		localVarReturnValue  []Function
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "FunctionApiService.GetFunctions")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/functions"
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 500 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
+type FunctionApiPutFunctionAliasRequest struct {
+	ctx context.Context
+	ApiService FunctionApi
+	name string
+	alias string
+	putFunctionAliasRequest *PutFunctionAliasRequest
+}
+
+func (r FunctionApiPutFunctionAliasRequest) PutFunctionAliasRequest(putFunctionAliasRequest PutFunctionAliasRequest) FunctionApiPutFunctionAliasRequest {
+	r.putFunctionAliasRequest = &putFunctionAliasRequest
+	return r
+}
+
+func (r FunctionApiPutFunctionAliasRequest) Execute() (*Function, *http.Response, error) {
+	return r.ApiService.PutFunctionAliasExecute(r)
+}
+
+/*
+PutFunctionAlias Create or update a function alias
+
+Create or update an alias splitting the invocations of the function between its revisions. The alias can be invoked using `/functions/{name}@{alias}/invoke`.
+
+ @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ @param name The name of the function
+ @param alias The name of the alias
+ @return FunctionApiPutFunctionAliasRequest
+*/
+func (a *FunctionApiService) PutFunctionAlias(ctx context.Context, name string, alias string) FunctionApiPutFunctionAliasRequest {
+	return FunctionApiPutFunctionAliasRequest{
+		ApiService: a,
+		ctx: ctx,
+		name: name,
+		alias: alias,
+	}
+}
+
+// Execute executes the request
+//  @return Function
+func (a *FunctionApiService) PutFunctionAliasExecute(r FunctionApiPutFunctionAliasRequest) (*Function, *http.Response, error) {
+	var (
+		localVarHTTPMethod   = http.MethodPut
+		localVarPostBody     interface{}
+		formFiles            []formFile
+		localVarReturnValue  *Function
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "FunctionApiService.PutFunctionAlias")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/functions/{name}/aliases/{alias}"
+	localVarPath = strings.Replace(localVarPath, "{"+"name"+"}", url.PathEscape(parameterValueToString(r.name, "name")), -1)
+	localVarPath = strings.Replace(localVarPath, "{"+"alias"+"}", url.PathEscape(parameterValueToString(r.alias, "alias")), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+	if r.putFunctionAliasRequest == nil {
+		return localVarReturnValue, nil, reportError("putFunctionAliasRequest is required and must be specified")
+	}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{"application/json"}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	// body params
+	localVarPostBody = r.putFunctionAliasRequest
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 404 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 500 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
+type FunctionApiRollbackFunctionRequest struct {
+	ctx context.Context
+	ApiService FunctionApi
+	name string
+	rollbackFunctionRequest *RollbackFunctionRequest
+}
+
+func (r FunctionApiRollbackFunctionRequest) RollbackFunctionRequest(rollbackFunctionRequest RollbackFunctionRequest) FunctionApiRollbackFunctionRequest {
+	r.rollbackFunctionRequest = &rollbackFunctionRequest
+	return r
+}
+
+func (r FunctionApiRollbackFunctionRequest) Execute() (*Function, *http.Response, error) {
+	return r.ApiService.RollbackFunctionExecute(r)
+}
+
+/*
+RollbackFunction Rollback a function
+
+Rollback a function to a previous revision. As revisions are immutable, a new revision is created with the definition of the targeted revision.
+
+ @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ @param name The name of the function
+ @return FunctionApiRollbackFunctionRequest
+*/
+func (a *FunctionApiService) RollbackFunction(ctx context.Context, name string) FunctionApiRollbackFunctionRequest {
+	return FunctionApiRollbackFunctionRequest{
+		ApiService: a,
+		ctx: ctx,
+		name: name,
+	}
+}
+
+// Execute executes the request
+//  @return Function
+func (a *FunctionApiService) RollbackFunctionExecute(r FunctionApiRollbackFunctionRequest) (*Function, *http.Response, error) {
+	var (
+		localVarHTTPMethod   = http.MethodPost
+		localVarPostBody     interface{}
+		formFiles            []formFile
+		localVarReturnValue  *Function
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "FunctionApiService.RollbackFunction")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/functions/{name}/rollback"
+	localVarPath = strings.Replace(localVarPath, "{"+"name"+"}", url.PathEscape(parameterValueToString(r.name, "name")), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+	if r.rollbackFunctionRequest == nil {
+		return localVarReturnValue, nil, reportError("rollbackFunctionRequest is required and must be specified")
+	}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{"application/json"}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	// body params
+	localVarPostBody = r.rollbackFunctionRequest
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 404 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 500 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
+type FunctionApiUpdateFunctionRequest struct {
+	ctx context.Context
+	ApiService FunctionApi
+	name string
+	updateFunctionRequest *UpdateFunctionRequest
+}
+
+func (r FunctionApiUpdateFunctionRequest) UpdateFunctionRequest(updateFunctionRequest UpdateFunctionRequest) FunctionApiUpdateFunctionRequest {
+	r.updateFunctionRequest = &updateFunctionRequest
+	return r
+}
+
+func (r FunctionApiUpdateFunctionRequest) Execute() (*Function, *http.Response, error) {
+	return r.ApiService.UpdateFunctionExecute(r)
+}
+
+/*
+UpdateFunction Update a function
+
+Update the image of an existing function. Instances running the previous image are torn down.
+
+ @param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+ @param name The name of the function
+ @return FunctionApiUpdateFunctionRequest
+*/
+func (a *FunctionApiService) UpdateFunction(ctx context.Context, name string) FunctionApiUpdateFunctionRequest {
+	return FunctionApiUpdateFunctionRequest{
+		ApiService: a,
+		ctx: ctx,
+		name: name,
+	}
+}
+
+// Execute executes the request
+//  @return Function
+func (a *FunctionApiService) UpdateFunctionExecute(r FunctionApiUpdateFunctionRequest) (*Function, *http.Response, error) {
+	var (
+		localVarHTTPMethod   = http.MethodPut
+		localVarPostBody     interface{}
+		formFiles            []formFile
+		localVarReturnValue  *Function
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "FunctionApiService.UpdateFunction")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/functions/{name}"
+	localVarPath = strings.Replace(localVarPath, "{"+"name"+"}", url.PathEscape(parameterValueToString(r.name, "name")), -1)
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+	if r.updateFunctionRequest == nil {
+		return localVarReturnValue, nil, reportError("updateFunctionRequest is required and must be specified")
+	}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{"application/json"}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	// body params
+	localVarPostBody = r.updateFunctionRequest
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 404 {
+			var v Error
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+					newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+					newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
 		if localVarHTTPResponse.StatusCode == 500 {
 			var v Error
 			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
@@ -21,6 +21,12 @@ var _ MappedNullable = &CreateFunctionRequest{}
 type CreateFunctionRequest struct {
 	Name *string `json:"name,omitempty"`
 	Image *string `json:"image,omitempty"`
+	// The number of instances kept permanently warm, they never expire
+	MinInstances *int32 `json:"minInstances,omitempty"`
+	// The number of instances the function never scales up above, defaults to the controller configuration if not set
+	MaxInstances *int32 `json:"maxInstances,omitempty"`
+	// The number of seconds an instance is kept warm after its last invocation, defaults to the controller configuration if not set
+	IdleTimeout *int32 `json:"idleTimeout,omitempty"`
 }
 
 // NewCreateFunctionRequest instantiates a new CreateFunctionRequest object
@@ -104,6 +110,102 @@ func (o *CreateFunctionRequest) SetImage(v string) {
 	o.Image = &v
 }
 
+// GetMinInstances returns the MinInstances field value if set, zero value otherwise.
+func (o *CreateFunctionRequest) GetMinInstances() int32 {
+	if o == nil || IsNil(o.MinInstances) {
+		var ret int32
+		return ret
+	}
+	return *o.MinInstances
+}
+
+// GetMinInstancesOk returns a tuple with the MinInstances field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *CreateFunctionRequest) GetMinInstancesOk() (*int32, bool) {
+	if o == nil || IsNil(o.MinInstances) {
+		return nil, false
+	}
+	return o.MinInstances, true
+}
+
+// HasMinInstances returns a boolean if a field has been set.
+func (o *CreateFunctionRequest) HasMinInstances() bool {
+	if o != nil && !IsNil(o.MinInstances) {
+		return true
+	}
+
+	return false
+}
+
+// SetMinInstances gets a reference to the given int32 and assigns it to the MinInstances field.
+func (o *CreateFunctionRequest) SetMinInstances(v int32) {
+	o.MinInstances = &v
+}
+
+// GetMaxInstances returns the MaxInstances field value if set, zero value otherwise.
+func (o *CreateFunctionRequest) GetMaxInstances() int32 {
+	if o == nil || IsNil(o.MaxInstances) {
+		var ret int32
+		return ret
+	}
+	return *o.MaxInstances
+}
+
+// GetMaxInstancesOk returns a tuple with the MaxInstances field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *CreateFunctionRequest) GetMaxInstancesOk() (*int32, bool) {
+	if o == nil || IsNil(o.MaxInstances) {
+		return nil, false
+	}
+	return o.MaxInstances, true
+}
+
+// HasMaxInstances returns a boolean if a field has been set.
+func (o *CreateFunctionRequest) HasMaxInstances() bool {
+	if o != nil && !IsNil(o.MaxInstances) {
+		return true
+	}
+
+	return false
+}
+
+// SetMaxInstances gets a reference to the given int32 and assigns it to the MaxInstances field.
+func (o *CreateFunctionRequest) SetMaxInstances(v int32) {
+	o.MaxInstances = &v
+}
+
+// GetIdleTimeout returns the IdleTimeout field value if set, zero value otherwise.
+func (o *CreateFunctionRequest) GetIdleTimeout() int32 {
+	if o == nil || IsNil(o.IdleTimeout) {
+		var ret int32
+		return ret
+	}
+	return *o.IdleTimeout
+}
+
+// GetIdleTimeoutOk returns a tuple with the IdleTimeout field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *CreateFunctionRequest) GetIdleTimeoutOk() (*int32, bool) {
+	if o == nil || IsNil(o.IdleTimeout) {
+		return nil, false
+	}
+	return o.IdleTimeout, true
+}
+
+// HasIdleTimeout returns a boolean if a field has been set.
+func (o *CreateFunctionRequest) HasIdleTimeout() bool {
+	if o != nil && !IsNil(o.IdleTimeout) {
+		return true
+	}
+
+	return false
+}
+
+// SetIdleTimeout gets a reference to the given int32 and assigns it to the IdleTimeout field.
+func (o *CreateFunctionRequest) SetIdleTimeout(v int32) {
+	o.IdleTimeout = &v
+}
+
 func (o CreateFunctionRequest) MarshalJSON() ([]byte, error) {
 	toSerialize,err := o.ToMap()
 	if err != nil {
@@ -120,6 +222,15 @@ func (o CreateFunctionRequest) ToMap() (map[string]interface{}, error) {
 	if !IsNil(o.Image) {
 		toSerialize["image"] = o.Image
 	}
+	if !IsNil(o.MinInstances) {
+		toSerialize["minInstances"] = o.MinInstances
+	}
+	if !IsNil(o.MaxInstances) {
+		toSerialize["maxInstances"] = o.MaxInstances
+	}
+	if !IsNil(o.IdleTimeout) {
+		toSerialize["idleTimeout"] = o.IdleTimeout
+	}
 	return toSerialize, nil
 }
 
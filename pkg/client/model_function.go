@@ -25,6 +25,18 @@ type Function struct {
 	Name string `json:"name"`
 	// The URL of the function image
 	Image string `json:"image"`
+	// The number of the latest revision of the function
+	Revision *int32 `json:"revision,omitempty"`
+	// The immutable history of the function, ordered by revision number
+	Revisions []Revision `json:"revisions,omitempty"`
+	// The aliases of the function, indexed by name
+	Aliases *map[string]Alias `json:"aliases,omitempty"`
+	// The number of instances kept permanently warm, they never expire
+	MinInstances *int32 `json:"minInstances,omitempty"`
+	// The number of instances the function never scales up above, zero means the controller default applies
+	MaxInstances *int32 `json:"maxInstances,omitempty"`
+	// The number of seconds an instance is kept warm after its last invocation, zero means the controller default applies
+	IdleTimeout *int32 `json:"idleTimeout,omitempty"`
 }
 
 // NewFunction instantiates a new Function object
@@ -126,6 +138,198 @@ func (o *Function) SetImage(v string) {
 	o.Image = v
 }
 
+// GetRevision returns the Revision field value if set, zero value otherwise.
+func (o *Function) GetRevision() int32 {
+	if o == nil || IsNil(o.Revision) {
+		var ret int32
+		return ret
+	}
+	return *o.Revision
+}
+
+// GetRevisionOk returns a tuple with the Revision field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *Function) GetRevisionOk() (*int32, bool) {
+	if o == nil || IsNil(o.Revision) {
+		return nil, false
+	}
+	return o.Revision, true
+}
+
+// HasRevision returns a boolean if a field has been set.
+func (o *Function) HasRevision() bool {
+	if o != nil && !IsNil(o.Revision) {
+		return true
+	}
+
+	return false
+}
+
+// SetRevision gets a reference to the given int32 and assigns it to the Revision field.
+func (o *Function) SetRevision(v int32) {
+	o.Revision = &v
+}
+
+// GetRevisions returns the Revisions field value if set, zero value otherwise.
+func (o *Function) GetRevisions() []Revision {
+	if o == nil || IsNil(o.Revisions) {
+		var ret []Revision
+		return ret
+	}
+	return o.Revisions
+}
+
+// GetRevisionsOk returns a tuple with the Revisions field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *Function) GetRevisionsOk() ([]Revision, bool) {
+	if o == nil || IsNil(o.Revisions) {
+		return nil, false
+	}
+	return o.Revisions, true
+}
+
+// HasRevisions returns a boolean if a field has been set.
+func (o *Function) HasRevisions() bool {
+	if o != nil && !IsNil(o.Revisions) {
+		return true
+	}
+
+	return false
+}
+
+// SetRevisions gets a reference to the given []Revision and assigns it to the Revisions field.
+func (o *Function) SetRevisions(v []Revision) {
+	o.Revisions = v
+}
+
+// GetAliases returns the Aliases field value if set, zero value otherwise.
+func (o *Function) GetAliases() map[string]Alias {
+	if o == nil || IsNil(o.Aliases) {
+		var ret map[string]Alias
+		return ret
+	}
+	return *o.Aliases
+}
+
+// GetAliasesOk returns a tuple with the Aliases field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *Function) GetAliasesOk() (*map[string]Alias, bool) {
+	if o == nil || IsNil(o.Aliases) {
+		return nil, false
+	}
+	return o.Aliases, true
+}
+
+// HasAliases returns a boolean if a field has been set.
+func (o *Function) HasAliases() bool {
+	if o != nil && !IsNil(o.Aliases) {
+		return true
+	}
+
+	return false
+}
+
+// SetAliases gets a reference to the given map[string]Alias and assigns it to the Aliases field.
+func (o *Function) SetAliases(v map[string]Alias) {
+	o.Aliases = &v
+}
+
+// GetMinInstances returns the MinInstances field value if set, zero value otherwise.
+func (o *Function) GetMinInstances() int32 {
+	if o == nil || IsNil(o.MinInstances) {
+		var ret int32
+		return ret
+	}
+	return *o.MinInstances
+}
+
+// GetMinInstancesOk returns a tuple with the MinInstances field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *Function) GetMinInstancesOk() (*int32, bool) {
+	if o == nil || IsNil(o.MinInstances) {
+		return nil, false
+	}
+	return o.MinInstances, true
+}
+
+// HasMinInstances returns a boolean if a field has been set.
+func (o *Function) HasMinInstances() bool {
+	if o != nil && !IsNil(o.MinInstances) {
+		return true
+	}
+
+	return false
+}
+
+// SetMinInstances gets a reference to the given int32 and assigns it to the MinInstances field.
+func (o *Function) SetMinInstances(v int32) {
+	o.MinInstances = &v
+}
+
+// GetMaxInstances returns the MaxInstances field value if set, zero value otherwise.
+func (o *Function) GetMaxInstances() int32 {
+	if o == nil || IsNil(o.MaxInstances) {
+		var ret int32
+		return ret
+	}
+	return *o.MaxInstances
+}
+
+// GetMaxInstancesOk returns a tuple with the MaxInstances field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *Function) GetMaxInstancesOk() (*int32, bool) {
+	if o == nil || IsNil(o.MaxInstances) {
+		return nil, false
+	}
+	return o.MaxInstances, true
+}
+
+// HasMaxInstances returns a boolean if a field has been set.
+func (o *Function) HasMaxInstances() bool {
+	if o != nil && !IsNil(o.MaxInstances) {
+		return true
+	}
+
+	return false
+}
+
+// SetMaxInstances gets a reference to the given int32 and assigns it to the MaxInstances field.
+func (o *Function) SetMaxInstances(v int32) {
+	o.MaxInstances = &v
+}
+
+// GetIdleTimeout returns the IdleTimeout field value if set, zero value otherwise.
+func (o *Function) GetIdleTimeout() int32 {
+	if o == nil || IsNil(o.IdleTimeout) {
+		var ret int32
+		return ret
+	}
+	return *o.IdleTimeout
+}
+
+// GetIdleTimeoutOk returns a tuple with the IdleTimeout field value if set, nil otherwise
+// and a boolean to check if the value has been set.
+func (o *Function) GetIdleTimeoutOk() (*int32, bool) {
+	if o == nil || IsNil(o.IdleTimeout) {
+		return nil, false
+	}
+	return o.IdleTimeout, true
+}
+
+// HasIdleTimeout returns a boolean if a field has been set.
+func (o *Function) HasIdleTimeout() bool {
+	if o != nil && !IsNil(o.IdleTimeout) {
+		return true
+	}
+
+	return false
+}
+
+// SetIdleTimeout gets a reference to the given int32 and assigns it to the IdleTimeout field.
+func (o *Function) SetIdleTimeout(v int32) {
+	o.IdleTimeout = &v
+}
+
 func (o Function) MarshalJSON() ([]byte, error) {
 	toSerialize,err := o.ToMap()
 	if err != nil {
@@ -141,6 +345,24 @@ func (o Function) ToMap() (map[string]interface{}, error) {
 	}
 	toSerialize["name"] = o.Name
 	toSerialize["image"] = o.Image
+	if !IsNil(o.Revision) {
+		toSerialize["revision"] = o.Revision
+	}
+	if !IsNil(o.Revisions) {
+		toSerialize["revisions"] = o.Revisions
+	}
+	if !IsNil(o.Aliases) {
+		toSerialize["aliases"] = o.Aliases
+	}
+	if !IsNil(o.MinInstances) {
+		toSerialize["minInstances"] = o.MinInstances
+	}
+	if !IsNil(o.MaxInstances) {
+		toSerialize["maxInstances"] = o.MaxInstances
+	}
+	if !IsNil(o.IdleTimeout) {
+		toSerialize["idleTimeout"] = o.IdleTimeout
+	}
 	return toSerialize, nil
 }
 
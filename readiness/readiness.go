@@ -0,0 +1,169 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/morty-faas/controller/metrics"
+	"github.com/morty-faas/controller/types"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+var (
+	ErrProbeTimeout = errors.New("instance didn't become healthy in time")
+)
+
+// Config hold the configuration about the instance readiness probes
+type Config struct {
+	// InitialBackoff is the delay before the first retry of a failed probe
+	InitialBackoff time.Duration `yaml:"initialBackoff"`
+	// MaxBackoff is the maximum delay between two probes
+	MaxBackoff time.Duration `yaml:"maxBackoff"`
+	// Timeout is the maximum duration allowed for an instance to become healthy
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Tracker keeps track of the function instances that are ready to receive requests.
+// Instances are probed in the background until they become healthy, and are then
+// remembered so the subsequent invocations don't need to probe them again.
+type Tracker struct {
+	cfg    *Config
+	client *http.Client
+
+	mu    sync.Mutex
+	ready map[string]struct{}
+	// probes contains the in-progress probes, indexed by instance ID,
+	// so concurrent invocations of the same instance share a single probe.
+	probes map[string]*probe
+}
+
+type probe struct {
+	done chan struct{}
+	err  error
+}
+
+// NewTracker initializes a new readiness tracker based on the given configuration.
+func NewTracker(cfg *Config) *Tracker {
+	return &Tracker{
+		cfg:    cfg,
+		client: &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		ready:  make(map[string]struct{}),
+		probes: make(map[string]*probe),
+	}
+}
+
+// IsReady returns true if the instance is known to be healthy.
+func (t *Tracker) IsReady(instanceId string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ready := t.ready[instanceId]
+	return ready
+}
+
+// WaitReady blocks until the instance is healthy, the probe times out or the context is cancelled.
+// It returns immediately if the instance is already known to be healthy.
+func (t *Tracker) WaitReady(ctx context.Context, instance *types.FnInstance) error {
+	t.mu.Lock()
+	if _, ready := t.ready[instance.Id]; ready {
+		t.mu.Unlock()
+		return nil
+	}
+
+	p, exists := t.probes[instance.Id]
+	if !exists {
+		p = &probe{done: make(chan struct{})}
+		t.probes[instance.Id] = p
+		// The probe isn't bound to the context of the caller, so it can be
+		// shared by the concurrent invocations targeting the same instance.
+		go t.run(instance, p)
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return p.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Forget removes the instance from the tracked instances. It must be called when the instance
+// is deleted or stops answering, so it will be probed again on the next invocation.
+func (t *Tracker) Forget(instanceId string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.ready, instanceId)
+}
+
+// run probes the instance with an exponential backoff until it becomes healthy or the probe times out.
+func (t *Tracker) run(instance *types.FnInstance, p *probe) {
+	ctx, cancel := context.WithTimeout(context.Background(), t.cfg.Timeout)
+	defer cancel()
+
+	endpoint := instance.Endpoint.String() + "/_/health"
+	backoff := t.cfg.InitialBackoff
+
+	for {
+		log.Debugf("Performing healthcheck request on Alpha: %s", endpoint)
+		err := t.probe(ctx, endpoint)
+		if err == nil {
+			break
+		}
+
+		log.Tracef("Healthcheck of instance %s failed, retrying in %v: %v", instance.Id, backoff, err)
+		metrics.HealthcheckRetries.WithLabelValues(instance.Function.Name).Inc()
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			p.err = fmt.Errorf("%w: %v", ErrProbeTimeout, err)
+			t.complete(instance.Id, p, false)
+			return
+		case <-timer.C:
+		}
+
+		if backoff *= 2; backoff > t.cfg.MaxBackoff {
+			backoff = t.cfg.MaxBackoff
+		}
+	}
+
+	log.Infof("Instance %s of function '%s' is healthy and ready to receive requests", instance.Id, instance.Function.Name)
+	t.complete(instance.Id, p, true)
+}
+
+// complete records the result of the probe and releases the waiting invocations
+func (t *Tracker) complete(instanceId string, p *probe, ready bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.probes, instanceId)
+	if ready {
+		t.ready[instanceId] = struct{}{}
+	}
+	close(p.done)
+}
+
+// probe is a helper function to perform a single healthcheck request against the Alpha agent
+func (t *Tracker) probe(ctx context.Context, endpoint string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected healthcheck status code: %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/morty-faas/controller/leader"
+	"github.com/morty-faas/controller/metrics"
+	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/scaling"
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Config hold the configuration of the reconciliation loop
+type Config struct {
+	// Interval is the duration between two reconciliations
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Drift describes the differences between the state and the orchestrator.
+type Drift struct {
+	// MissingFunctions are provisioned into the orchestrator but unknown to the state
+	MissingFunctions []string `json:"missingFunctions"`
+	// UnprovisionedFunctions are known to the state but not provisioned into the orchestrator
+	UnprovisionedFunctions []string `json:"unprovisionedFunctions"`
+	// OrphanedInstances are running into the orchestrator but not registered into the state
+	OrphanedInstances []*types.FnInstanceRef `json:"orphanedInstances"`
+	// StaleInstances are registered into the state but not running anymore
+	StaleInstances []*types.FnInstanceRef `json:"staleInstances"`
+
+	missing []*types.Function
+}
+
+// IsEmpty returns true if the state and the orchestrator are in sync.
+func (d *Drift) IsEmpty() bool {
+	return len(d.MissingFunctions) == 0 &&
+		len(d.UnprovisionedFunctions) == 0 &&
+		len(d.OrphanedInstances) == 0 &&
+		len(d.StaleInstances) == 0
+}
+
+// Reconciler periodically corrects the drift between the state and the orchestrator: the functions
+// missing from the state are registered again, the stale instances are removed from the state, and
+// the orphaned instances are torn down. The functions that aren't provisioned into the orchestrator
+// are only reported, as their definition may still be needed to provision them again.
+type Reconciler struct {
+	cfg        *Config
+	state      state.State
+	orch       orchestration.Orchestrator
+	autoscaler *scaling.Autoscaler
+	elector    *leader.Elector
+
+	// suspects contains the orphaned instances found by the previous reconciliation, indexed by reference.
+	// An instance is only torn down if it is still orphaned on the next reconciliation, as it may be
+	// running for a short time before being registered into the state.
+	suspects map[string]struct{}
+}
+
+// NewReconciler initializes a new reconciler. Only the leader controller corrects the drift.
+func NewReconciler(cfg *Config, s state.State, orch orchestration.Orchestrator, autoscaler *scaling.Autoscaler, elector *leader.Elector) *Reconciler {
+	return &Reconciler{
+		cfg:        cfg,
+		state:      s,
+		orch:       orch,
+		autoscaler: autoscaler,
+		elector:    elector,
+		suspects:   make(map[string]struct{}),
+	}
+}
+
+// Run periodically reconciles the state with the orchestrator, until the context is cancelled.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if r.elector.IsLeader() {
+				r.reconcile(ctx)
+			}
+		}
+	}
+}
+
+// Diff computes the drift between the state and the orchestrator, without correcting it.
+func (r *Reconciler) Diff(ctx context.Context) (*Drift, error) {
+	registered, err := r.state.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The records are retrieved before the running instances, so an instance
+	// can't be registered and torn down in between without being noticed
+	records, err := r.state.ListInstances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	provisioned, err := r.orch.GetFunctions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := &Drift{
+		MissingFunctions:       []string{},
+		UnprovisionedFunctions: []string{},
+		OrphanedInstances:      []*types.FnInstanceRef{},
+		StaleInstances:         []*types.FnInstanceRef{},
+	}
+
+	known := map[string]struct{}{}
+	for _, fn := range registered {
+		known[fn.Name] = struct{}{}
+	}
+
+	running := map[string]*types.FnInstanceRef{}
+	deployed := map[string]struct{}{}
+	for _, fn := range provisioned {
+		deployed[fn.Name] = struct{}{}
+		if _, exists := known[fn.Name]; !exists {
+			drift.MissingFunctions = append(drift.MissingFunctions, fn.Name)
+			drift.missing = append(drift.missing, fn)
+		}
+
+		// The orchestrator definition holds all the deployed revisions of the function
+		instances, err := r.orch.GetFunctionInstances(ctx, fn)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range instances {
+			running[instance.Ref().String()] = instance.Ref()
+		}
+	}
+
+	for _, fn := range registered {
+		if _, exists := deployed[fn.Name]; !exists {
+			drift.UnprovisionedFunctions = append(drift.UnprovisionedFunctions, fn.Name)
+		}
+	}
+
+	recorded := map[string]struct{}{}
+	for _, record := range records {
+		key := record.Ref().String()
+		recorded[key] = struct{}{}
+		if _, exists := running[key]; !exists {
+			drift.StaleInstances = append(drift.StaleInstances, record.Ref())
+		}
+	}
+
+	for key, ref := range running {
+		if _, exists := recorded[key]; !exists {
+			drift.OrphanedInstances = append(drift.OrphanedInstances, ref)
+		}
+	}
+
+	sort.Strings(drift.MissingFunctions)
+	sort.Strings(drift.UnprovisionedFunctions)
+	sortRefs(drift.OrphanedInstances)
+	sortRefs(drift.StaleInstances)
+
+	return drift, nil
+}
+
+// reconcile is a helper function to compute the drift and correct it
+func (r *Reconciler) reconcile(ctx context.Context) {
+	drift, err := r.Diff(ctx)
+	if err != nil {
+		log.Errorf("Failed to compute the drift between the state and the orchestrator: %v", err)
+		return
+	}
+
+	metrics.Drift.WithLabelValues("missing_functions").Set(float64(len(drift.MissingFunctions)))
+	metrics.Drift.WithLabelValues("unprovisioned_functions").Set(float64(len(drift.UnprovisionedFunctions)))
+	metrics.Drift.WithLabelValues("orphaned_instances").Set(float64(len(drift.OrphanedInstances)))
+	metrics.Drift.WithLabelValues("stale_instances").Set(float64(len(drift.StaleInstances)))
+
+	if drift.IsEmpty() {
+		log.Debug("State is in sync with the orchestrator")
+		r.suspects = make(map[string]struct{})
+		return
+	}
+
+	log.Warnf("Drift detected between the state and the orchestrator: %d missing function(s), %d unprovisioned function(s), %d orphaned instance(s), %d stale instance(s)",
+		len(drift.MissingFunctions), len(drift.UnprovisionedFunctions), len(drift.OrphanedInstances), len(drift.StaleInstances))
+
+	for _, fn := range drift.missing {
+		if err := r.state.Set(ctx, fn); err != nil {
+			log.Errorf("Failed to register function '%s' into the state: %v", fn.Name, err)
+			continue
+		}
+		log.Infof("Function '%s' registered again into the state", fn.Name)
+	}
+
+	for _, name := range drift.UnprovisionedFunctions {
+		log.Warnf("Function '%s' isn't provisioned into the orchestrator", name)
+	}
+
+	for _, ref := range drift.StaleInstances {
+		r.autoscaler.Forget(ref)
+		if err := r.state.DeleteInstance(ctx, ref); err != nil {
+			log.Errorf("Failed to remove stale instance %s from the state: %v", ref, err)
+			continue
+		}
+		log.Infof("Stale instance %s removed from the state", ref)
+	}
+
+	suspects := make(map[string]struct{})
+	for _, ref := range drift.OrphanedInstances {
+		key := ref.String()
+		if _, suspected := r.suspects[key]; !suspected {
+			log.Debugf("Instance %s is orphaned, it will be torn down if it is still orphaned on the next reconciliation", ref)
+			suspects[key] = struct{}{}
+			continue
+		}
+
+		if err := r.deleteOrphan(ctx, ref); err != nil {
+			log.Errorf("Failed to tear down orphaned instance %s: %v", ref, err)
+			suspects[key] = struct{}{}
+			continue
+		}
+		log.Infof("Orphaned instance %s torn down", ref)
+	}
+	r.suspects = suspects
+}
+
+// deleteOrphan is a helper function to tear down an orphaned instance, and remove its expiry from the state
+func (r *Reconciler) deleteOrphan(ctx context.Context, ref *types.FnInstanceRef) error {
+	r.autoscaler.Forget(ref)
+
+	err := r.orch.DeleteFunctionInstance(ctx, ref)
+	if err != nil && !errors.Is(err, orchestration.ErrInstanceNotFound) {
+		return err
+	}
+
+	return r.state.DeleteInstance(ctx, ref)
+}
+
+// sortRefs is a helper function to sort the references by their string form
+func sortRefs(refs []*types.FnInstanceRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].String() < refs[j].String()
+	})
+}
@@ -0,0 +1,259 @@
+package reconciliation
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/morty-faas/controller/internal/testutil"
+	"github.com/morty-faas/controller/leader"
+	"github.com/morty-faas/controller/scaling"
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/state/memory"
+	"github.com/morty-faas/controller/types"
+)
+
+var (
+	weatho = &types.Function{Id: "weatho-r1", Name: "weatho", Revision: 1}
+	hello  = &types.Function{Id: "hello-r1", Name: "hello", Revision: 1}
+)
+
+// newTestReconciler initializes a reconciler backed by the memory state. If isLeader is false,
+// the leadership is held by another controller.
+func newTestReconciler(t *testing.T, orch *testutil.Orchestrator, isLeader bool) (*Reconciler, state.State) {
+	t.Helper()
+
+	s := memory.NewState(func(ref *types.FnInstanceRef) {})
+	t.Cleanup(func() { s.Close() })
+
+	var elector *leader.Elector
+	if isLeader {
+		elector = leader.NewElector(&leader.Config{}, s)
+	} else {
+		// The lock is never acquired, as the election isn't run
+		elector = leader.NewElector(&leader.Config{}, &testutil.Locker{State: s})
+	}
+
+	autoscaler := scaling.NewAutoscaler(&scaling.Config{Interval: time.Minute}, s, orch, testutil.NewTracker(), elector)
+	return NewReconciler(&Config{Interval: 10 * time.Millisecond}, s, orch, autoscaler, elector), s
+}
+
+// register is a helper function to register the functions and the instance records into the state
+func register(t *testing.T, s state.State, functions []*types.Function, records []*types.FnInstance) {
+	t.Helper()
+
+	ctx := context.Background()
+	for _, fn := range functions {
+		if err := s.Set(ctx, fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, instance := range records {
+		if err := s.SetInstance(ctx, instance.Record()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// refs is a helper function to format the references as strings
+func refs(list []*types.FnInstanceRef) []string {
+	s := []string{}
+	for _, ref := range list {
+		s = append(s, ref.String())
+	}
+	return s
+}
+
+// recorded is a helper function to return the sorted references of the instances recorded into the state
+func recorded(t *testing.T, s state.State) []string {
+	t.Helper()
+
+	records, err := s.ListInstances(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorded := []string{}
+	for _, record := range records {
+		recorded = append(recorded, record.Ref().String())
+	}
+	sort.Strings(recorded)
+	return recorded
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDiff(t *testing.T) {
+	running := testutil.NewInstance(weatho, "running")
+	orphaned := testutil.NewInstance(weatho, "orphaned")
+	stale := testutil.NewInstance(weatho, "stale")
+
+	tests := []struct {
+		name        string
+		registered  []*types.Function
+		provisioned []*types.Function
+		running     []*types.FnInstance
+		recorded    []*types.FnInstance
+
+		missing, unprovisioned, orphaned, stale []string
+	}{
+		{
+			name:        "in sync",
+			registered:  []*types.Function{weatho},
+			provisioned: []*types.Function{weatho},
+			running:     []*types.FnInstance{running},
+			recorded:    []*types.FnInstance{running},
+		},
+		{
+			name:        "missing function",
+			registered:  []*types.Function{weatho},
+			provisioned: []*types.Function{weatho, hello},
+			missing:     []string{"hello"},
+		},
+		{
+			name:          "unprovisioned function",
+			registered:    []*types.Function{weatho, hello},
+			provisioned:   []*types.Function{weatho},
+			unprovisioned: []string{"hello"},
+		},
+		{
+			name:        "orphaned instance",
+			registered:  []*types.Function{weatho},
+			provisioned: []*types.Function{weatho},
+			running:     []*types.FnInstance{running, orphaned},
+			recorded:    []*types.FnInstance{running},
+			orphaned:    []string{orphaned.Ref().String()},
+		},
+		{
+			name:        "stale instance",
+			registered:  []*types.Function{weatho},
+			provisioned: []*types.Function{weatho},
+			running:     []*types.FnInstance{running},
+			recorded:    []*types.FnInstance{running, stale},
+			stale:       []string{stale.Ref().String()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orch := &testutil.Orchestrator{Functions: tt.provisioned, Instances: tt.running}
+			r, s := newTestReconciler(t, orch, true)
+			register(t, s, tt.registered, tt.recorded)
+
+			drift, err := r.Diff(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !equal(drift.MissingFunctions, tt.missing) {
+				t.Errorf("missing functions = %v, want %v", drift.MissingFunctions, tt.missing)
+			}
+			if !equal(drift.UnprovisionedFunctions, tt.unprovisioned) {
+				t.Errorf("unprovisioned functions = %v, want %v", drift.UnprovisionedFunctions, tt.unprovisioned)
+			}
+			if got := refs(drift.OrphanedInstances); !equal(got, tt.orphaned) {
+				t.Errorf("orphaned instances = %v, want %v", got, tt.orphaned)
+			}
+			if got := refs(drift.StaleInstances); !equal(got, tt.stale) {
+				t.Errorf("stale instances = %v, want %v", got, tt.stale)
+			}
+			if empty := len(tt.missing)+len(tt.unprovisioned)+len(tt.orphaned)+len(tt.stale) == 0; drift.IsEmpty() != empty {
+				t.Errorf("IsEmpty() = %t, want %t", drift.IsEmpty(), empty)
+			}
+		})
+	}
+}
+
+func TestReconcileCorrectsDrift(t *testing.T) {
+	ctx := context.Background()
+	running, stale := testutil.NewInstance(weatho, "running"), testutil.NewInstance(weatho, "stale")
+	orch := &testutil.Orchestrator{Functions: []*types.Function{weatho, hello}, Instances: []*types.FnInstance{running}}
+	r, s := newTestReconciler(t, orch, true)
+	register(t, s, []*types.Function{weatho}, []*types.FnInstance{running, stale})
+
+	r.reconcile(ctx)
+
+	if _, err := s.Get(ctx, hello.Name); err != nil {
+		t.Errorf("Get(%s) = %v, want the missing function to be registered again", hello.Name, err)
+	}
+	if got, want := recorded(t, s), []string{running.Ref().String()}; !equal(got, want) {
+		t.Errorf("recorded instances = %v, want %v", got, want)
+	}
+}
+
+func TestReconcileDeletesOrphanOnSecondStrike(t *testing.T) {
+	ctx := context.Background()
+	orphaned, registered := testutil.NewInstance(weatho, "orphaned"), testutil.NewInstance(weatho, "registered")
+	orch := &testutil.Orchestrator{Functions: []*types.Function{weatho}, Instances: []*types.FnInstance{orphaned, registered}}
+	r, s := newTestReconciler(t, orch, true)
+	register(t, s, []*types.Function{weatho}, nil)
+
+	// The first reconciliation only suspects both instances
+	r.reconcile(ctx)
+	if deleted := orch.Deleted(); len(deleted) != 0 {
+		t.Fatalf("deleted instances = %v, want none on the first strike", deleted)
+	}
+
+	// One of them is registered in the meantime, so it isn't orphaned anymore
+	register(t, s, nil, []*types.FnInstance{registered})
+	r.reconcile(ctx)
+	if deleted, want := orch.Deleted(), []string{orphaned.Id}; !equal(deleted, want) {
+		t.Fatalf("deleted instances = %v, want %v", deleted, want)
+	}
+
+	// The orphan is gone, so the state is in sync again
+	drift, err := r.Diff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !drift.IsEmpty() {
+		t.Fatalf("drift = %+v, want none", drift)
+	}
+}
+
+func TestRunOnlyOnLeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		isLeader bool
+		want     []string
+	}{
+		{"leader", true, []string{}},
+		{"follower", false, []string{"weatho-r1/stale"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stale := testutil.NewInstance(weatho, "stale")
+			orch := &testutil.Orchestrator{Functions: []*types.Function{weatho}}
+			r, s := newTestReconciler(t, orch, tt.isLeader)
+			register(t, s, []*types.Function{weatho}, []*types.FnInstance{stale})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			stopped := make(chan struct{})
+			go func() {
+				r.Run(ctx)
+				close(stopped)
+			}()
+
+			// Leave the time for a few reconciliations
+			time.Sleep(10 * r.cfg.Interval)
+			cancel()
+			<-stopped
+
+			if got := recorded(t, s); !equal(got, tt.want) {
+				t.Fatalf("recorded instances = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,536 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/morty-faas/controller/leader"
+	"github.com/morty-faas/controller/metrics"
+	"github.com/morty-faas/controller/orchestration"
+	"github.com/morty-faas/controller/readiness"
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/types"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrNoInstanceAvailable = errors.New("no instance of the function is available")
+)
+
+// Config hold the configuration of the autoscaler
+type Config struct {
+	// TargetConcurrency is the number of in-flight requests an instance should handle.
+	// A new instance is created when the average concurrency of a function exceeds it.
+	TargetConcurrency int `yaml:"targetConcurrency"`
+	// MaxInstances is the default maximum number of instances of a function
+	MaxInstances int `yaml:"maxInstances"`
+	// ScaleDownDelay is the duration an instance must stay idle before being torn down
+	ScaleDownDelay time.Duration `yaml:"scaleDownDelay"`
+	// Interval is the duration between two scale down evaluations
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Autoscaler distributes the invocations between the instances of the functions, and scales
+// the number of instances according to the number of in-flight requests they are handling.
+type Autoscaler struct {
+	cfg     *Config
+	state   state.State
+	orch    orchestration.Orchestrator
+	tracker *readiness.Tracker
+	elector *leader.Elector
+
+	mu sync.Mutex
+	// pools contains the tracked instances, indexed by the orchestrator identifier of the function revision
+	pools map[string]*pool
+}
+
+type pool struct {
+	fn        *types.Function
+	instances map[string]*trackedInstance
+	scalingUp bool
+}
+
+type trackedInstance struct {
+	instance *types.FnInstance
+	inFlight int
+	lastUsed time.Time
+	// published is the date of the last invocation published into the state
+	published time.Time
+}
+
+// NewAutoscaler initializes a new autoscaler. New instances are only used
+// once the readiness tracker reports them as ready to receive requests.
+// The idle instances are only scaled down, and the minimum instances only
+// kept warm, by the leader controller.
+func NewAutoscaler(cfg *Config, s state.State, orch orchestration.Orchestrator, tracker *readiness.Tracker, elector *leader.Elector) *Autoscaler {
+	return &Autoscaler{
+		cfg:     cfg,
+		state:   s,
+		orch:    orch,
+		tracker: tracker,
+		elector: elector,
+		pools:   make(map[string]*pool),
+	}
+}
+
+// Acquire returns the least loaded instance of the function, and accounts a new in-flight request on it.
+// The caller must call Release once the request has been handled.
+func (a *Autoscaler) Acquire(ctx context.Context, fn *types.Function) (*types.FnInstance, error) {
+	a.mu.Lock()
+	p, exists := a.pools[fn.Id]
+	empty := !exists || len(p.instances) == 0
+	a.mu.Unlock()
+
+	if empty {
+		if err := a.discover(ctx, fn); err != nil {
+			return nil, err
+		}
+	}
+
+	a.mu.Lock()
+	p = a.getPool(fn)
+	// Keep the latest definition of the function, as its scaling bounds may have changed
+	p.fn = fn
+
+	var selected *trackedInstance
+	for _, t := range p.instances {
+		if selected == nil || t.inFlight < selected.inFlight {
+			selected = t
+		}
+	}
+
+	// The instances may have been forgotten in the meantime
+	if selected == nil {
+		a.mu.Unlock()
+		return nil, ErrNoInstanceAvailable
+	}
+
+	selected.inFlight++
+	selected.lastUsed = time.Now()
+
+	// The invocations are published at most once per interval, the following ones are published by Run
+	publish := selected.lastUsed.Sub(selected.published) >= a.cfg.Interval
+	if publish {
+		selected.published = selected.lastUsed
+	}
+
+	if a.shouldScaleUp(p) {
+		p.scalingUp = true
+		go a.scaleUp(fn)
+	}
+
+	instance, at := selected.instance, selected.lastUsed
+	a.mu.Unlock()
+
+	if publish {
+		a.publish(ctx, instance.Ref(), at)
+	}
+	return instance, nil
+}
+
+// Release accounts the end of a request handled by the instance.
+func (a *Autoscaler) Release(instance *types.FnInstance) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if t := a.tracked(instance.Ref()); t != nil && t.inFlight > 0 {
+		t.inFlight--
+		t.lastUsed = time.Now()
+	}
+}
+
+// Forget stops tracking the instance. It must be called when the instance is deleted
+// or stops answering, so no more requests are sent to it.
+func (a *Autoscaler) Forget(ref *types.FnInstanceRef) {
+	a.tracker.Forget(ref.InstanceId)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if p, exists := a.pools[ref.FunctionId]; exists {
+		delete(p.instances, ref.InstanceId)
+		if len(p.instances) == 0 && !p.scalingUp {
+			delete(a.pools, ref.FunctionId)
+		}
+	}
+}
+
+// Run periodically publishes the last invocations of the instances into the state, scales down the idle
+// instances and keeps the minimum instances of the functions warm, until the context is cancelled. As each
+// controller only knows about the requests it proxies, the idle instances are evaluated by the leader from
+// the invocations published by all the controllers.
+func (a *Autoscaler) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	a.keepWarm(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.publishInvocations(ctx)
+			a.scaleDown(ctx)
+			a.keepWarm(ctx)
+		}
+	}
+}
+
+// discover is a helper function to register the running instances of the function revision.
+// If the function doesn't have any running instance, a new one is deployed.
+func (a *Autoscaler) discover(ctx context.Context, fn *types.Function) error {
+	all, err := a.orch.GetFunctionInstances(ctx, fn)
+	if err != nil {
+		return err
+	}
+
+	var instances []*types.FnInstance
+	for _, instance := range all {
+		if instance.Function.Revision == fn.Revision {
+			instances = append(instances, instance)
+		}
+	}
+
+	if len(instances) == 0 {
+		instance, err := a.orch.GetFunctionInstance(ctx, fn)
+		if err != nil {
+			return err
+		}
+		instances = append(instances, instance)
+	}
+
+	a.mu.Lock()
+	p := a.getPool(fn)
+	for _, instance := range instances {
+		p.track(instance)
+	}
+	a.mu.Unlock()
+
+	a.record(ctx, instances...)
+	return nil
+}
+
+// shouldScaleUp returns true if the average concurrency of the pool exceeds the target
+// and the function is allowed to have more instances. The lock must be held by the caller.
+func (a *Autoscaler) shouldScaleUp(p *pool) bool {
+	maxInstances := p.fn.MaxInstances
+	if maxInstances == 0 {
+		maxInstances = a.cfg.MaxInstances
+	}
+
+	if p.scalingUp || len(p.instances) >= maxInstances {
+		return false
+	}
+
+	inFlight := 0
+	for _, t := range p.instances {
+		inFlight += t.inFlight
+	}
+
+	return inFlight > len(p.instances)*a.cfg.TargetConcurrency
+}
+
+// scaleUp is a helper function to deploy a new instance of the function when its concurrency exceeds the target.
+func (a *Autoscaler) scaleUp(fn *types.Function) {
+	defer func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.getPool(fn).scalingUp = false
+	}()
+
+	instance, err := a.provision(context.Background(), fn)
+	if err != nil {
+		log.Errorf("Failed to scale up function '%s': %v", fn.Name, err)
+		return
+	}
+
+	log.Infof("Function '%s' scaled up with instance %s", fn.Name, instance.Id)
+	metrics.ScalingEvents.WithLabelValues(fn.Name, "up").Inc()
+}
+
+// provision is a helper function to deploy a new instance of the function. The instance
+// is added to the pool once it is ready to receive requests.
+func (a *Autoscaler) provision(ctx context.Context, fn *types.Function) (*types.FnInstance, error) {
+	instance, err := a.orch.CreateFunctionInstance(ctx, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.tracker.WaitReady(ctx, instance); err != nil {
+		log.Errorf("Instance %s of function '%s' didn't become ready, tearing it down: %v", instance.Id, fn.Name, err)
+		if err := a.orch.DeleteFunctionInstance(ctx, instance.Ref()); err != nil {
+			log.Errorf("Failed to delete instance %s: %v", instance.Id, err)
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	a.mu.Lock()
+	a.getPool(fn).track(instance).published = now
+	a.mu.Unlock()
+
+	a.record(ctx, instance)
+	// The instance is considered as invoked once created, so the leader doesn't scale it down right away
+	a.publish(ctx, instance.Ref(), now)
+	return instance, nil
+}
+
+// publish is a helper function to record the last invocation of the instance into the state
+func (a *Autoscaler) publish(ctx context.Context, ref *types.FnInstanceRef, at time.Time) {
+	if err := a.state.SetLastInvocation(ctx, ref, at); err != nil {
+		log.Warnf("Failed to record the last invocation of instance %s into the state: %v", ref.InstanceId, err)
+	}
+}
+
+// publishInvocations records into the state the last invocation of the instances used since their last
+// publication. The instances handling requests are published as invoked now, so they aren't considered
+// as idle whatever the duration of the requests.
+func (a *Autoscaler) publishInvocations(ctx context.Context) {
+	invocations := map[*types.FnInstanceRef]time.Time{}
+
+	now := time.Now()
+	a.mu.Lock()
+	for _, p := range a.pools {
+		for _, t := range p.instances {
+			at := t.lastUsed
+			if t.inFlight > 0 {
+				at = now
+			}
+			if at.After(t.published) {
+				t.published = at
+				invocations[t.instance.Ref()] = at
+			}
+		}
+	}
+	a.mu.Unlock()
+
+	for ref, at := range invocations {
+		a.publish(ctx, ref, at)
+	}
+}
+
+// record is a helper function to register the tracked instances into the state
+func (a *Autoscaler) record(ctx context.Context, instances ...*types.FnInstance) {
+	for _, instance := range instances {
+		if err := a.state.SetInstance(ctx, instance.Record()); err != nil {
+			log.Warnf("Failed to record instance %s into the state: %v", instance.Id, err)
+		}
+	}
+}
+
+// keepWarm ensures the functions declaring a minimum number of instances have enough running instances.
+// The instances that disappeared from the orchestrator are forgotten and replaced.
+func (a *Autoscaler) keepWarm(ctx context.Context) {
+	if !a.elector.IsLeader() {
+		return
+	}
+
+	functions, err := a.state.List(ctx)
+	if err != nil {
+		log.Errorf("Failed to retrieve the functions to keep warm: %v", err)
+		return
+	}
+
+	for _, fn := range functions {
+		if fn.MinInstances == 0 {
+			continue
+		}
+
+		all, err := a.orch.GetFunctionInstances(ctx, fn)
+		if err != nil {
+			log.Errorf("Failed to retrieve the instances of function '%s': %v", fn.Name, err)
+			continue
+		}
+
+		running := map[string]*types.FnInstance{}
+		for _, instance := range all {
+			if instance.Function.Revision == fn.Revision {
+				running[instance.Id] = instance
+			}
+		}
+
+		var vanished, tracked []*types.FnInstance
+		a.mu.Lock()
+		p := a.getPool(fn)
+		p.fn = fn
+		for id, t := range p.instances {
+			if _, exists := running[id]; !exists {
+				log.Warnf("Instance %s of function '%s' disappeared", id, fn.Name)
+				delete(p.instances, id)
+				vanished = append(vanished, t.instance)
+			}
+		}
+		for _, instance := range running {
+			p.track(instance)
+			tracked = append(tracked, instance)
+		}
+		a.mu.Unlock()
+
+		for _, instance := range vanished {
+			a.tracker.Forget(instance.Id)
+			if err := a.state.DeleteInstance(ctx, instance.Ref()); err != nil {
+				log.Warnf("Failed to remove instance %s from the state: %v", instance.Id, err)
+			}
+		}
+		a.record(ctx, tracked...)
+
+		for i := len(running); i < fn.MinInstances; i++ {
+			instance, err := a.provision(ctx, fn)
+			if err != nil {
+				log.Errorf("Failed to keep function '%s' warm: %v", fn.Name, err)
+				break
+			}
+			log.Infof("Function '%s' kept warm with instance %s", fn.Name, instance.Id)
+		}
+	}
+}
+
+// scaleDown tears down the instances which haven't been invoked for longer than the scale down delay, by any
+// of the controllers. It only runs on the leader, which evaluates all the instances recorded into the state.
+// The minimum instances of the latest revision of the function are kept, as well as the last instance of each
+// revision which will be deleted once its keep-warm period expires.
+func (a *Autoscaler) scaleDown(ctx context.Context) {
+	if !a.elector.IsLeader() {
+		return
+	}
+
+	records, err := a.state.ListInstances(ctx)
+	if err != nil {
+		log.Errorf("Failed to retrieve the instances to scale down: %v", err)
+		return
+	}
+
+	// The instances are grouped by function revision
+	revisions := map[string][]*types.FnInstanceRecord{}
+	for _, record := range records {
+		revisions[record.FunctionId] = append(revisions[record.FunctionId], record)
+	}
+
+	var victims []*types.FnInstanceRecord
+	for _, records := range revisions {
+		fn, err := a.getRevision(ctx, records[0])
+		if err != nil {
+			log.Errorf("Failed to retrieve the function of instance %s: %v", records[0].InstanceId, err)
+			continue
+		}
+		// The instances of the unknown functions are handled by the reconciliation
+		if fn == nil {
+			continue
+		}
+
+		floor := fn.MinWarmInstances()
+		if floor < 1 {
+			floor = 1
+		}
+
+		idle := map[*types.FnInstanceRecord]time.Time{}
+		var candidates []*types.FnInstanceRecord
+		for _, record := range records {
+			if lastUsed, ok := a.idleSince(ctx, record.Ref()); ok {
+				idle[record] = lastUsed
+				candidates = append(candidates, record)
+			}
+		}
+
+		// The instances idle for the longest time are removed first
+		sort.Slice(candidates, func(i, j int) bool {
+			return idle[candidates[i]].Before(idle[candidates[j]])
+		})
+
+		remaining := len(records)
+		for _, record := range candidates {
+			if remaining <= floor {
+				break
+			}
+			victims = append(victims, record)
+			remaining--
+		}
+	}
+
+	for _, record := range victims {
+		log.Infof("Scaling down function '%s' by tearing down idle instance %s", record.FunctionName, record.InstanceId)
+
+		a.Forget(record.Ref())
+		err := a.orch.DeleteFunctionInstance(ctx, record.Ref())
+		if err != nil && !errors.Is(err, orchestration.ErrInstanceNotFound) {
+			log.Errorf("Failed to delete instance %s: %v", record.InstanceId, err)
+			continue
+		}
+
+		// The keep-warm period and the last invocation of the instance are removed along with its record
+		if err := a.state.DeleteInstance(ctx, record.Ref()); err != nil {
+			log.Warnf("Failed to remove instance %s from the state: %v", record.InstanceId, err)
+		}
+
+		metrics.ScalingEvents.WithLabelValues(record.FunctionName, "down").Inc()
+	}
+}
+
+// getRevision is a helper function to retrieve the function revision of the recorded instance.
+// It returns nil if the function or its revision doesn't exist anymore.
+func (a *Autoscaler) getRevision(ctx context.Context, record *types.FnInstanceRecord) (*types.Function, error) {
+	fn, err := a.state.Get(ctx, record.FunctionName)
+	if errors.Is(err, state.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fn.AtRevision(record.Revision), nil
+}
+
+// idleSince is a helper function to return the date of the last invocation of the instance, if it hasn't been
+// invoked for longer than the scale down delay. The invocations of this controller which haven't been published
+// yet are accounted for, and an instance without any published invocation is considered as idle.
+func (a *Autoscaler) idleSince(ctx context.Context, ref *types.FnInstanceRef) (time.Time, bool) {
+	lastUsed, err := a.state.GetLastInvocation(ctx, ref)
+	if err != nil && !errors.Is(err, state.ErrKeyNotFound) {
+		log.Warnf("Failed to retrieve the last invocation of instance %s: %v", ref.InstanceId, err)
+		return time.Time{}, false
+	}
+
+	busy := false
+	a.mu.Lock()
+	if t := a.tracked(ref); t != nil {
+		busy = t.inFlight > 0
+		if t.lastUsed.After(lastUsed) {
+			lastUsed = t.lastUsed
+		}
+	}
+	a.mu.Unlock()
+
+	return lastUsed, !busy && time.Since(lastUsed) > a.cfg.ScaleDownDelay
+}
+
+// getPool returns the pool of the function revision, and creates it if needed.
+// The lock must be held by the caller.
+func (a *Autoscaler) getPool(fn *types.Function) *pool {
+	p, exists := a.pools[fn.Id]
+	if !exists {
+		p = &pool{fn: fn, instances: make(map[string]*trackedInstance)}
+		a.pools[fn.Id] = p
+	}
+	return p
+}
+
+// tracked returns the tracked instance with the given reference, or nil if it isn't tracked.
+// The lock must be held by the caller.
+func (a *Autoscaler) tracked(ref *types.FnInstanceRef) *trackedInstance {
+	if p, exists := a.pools[ref.FunctionId]; exists {
+		return p.instances[ref.InstanceId]
+	}
+	return nil
+}
+
+// track adds the instance to the pool if it isn't already tracked, and returns it.
+func (p *pool) track(instance *types.FnInstance) *trackedInstance {
+	t, exists := p.instances[instance.Id]
+	if !exists {
+		t = &trackedInstance{instance: instance, lastUsed: time.Now()}
+		p.instances[instance.Id] = t
+	}
+	return t
+}
@@ -0,0 +1,164 @@
+package scaling
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/morty-faas/controller/internal/testutil"
+	"github.com/morty-faas/controller/leader"
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/state/memory"
+	"github.com/morty-faas/controller/types"
+)
+
+var testFunction = &types.Function{Id: "weatho-r1", Name: "weatho", Revision: 1}
+
+// newTestAutoscaler initializes an autoscaler backed by the memory state, which holds the test function
+func newTestAutoscaler(t *testing.T, orch *testutil.Orchestrator, isLeader bool) (*Autoscaler, state.State) {
+	t.Helper()
+
+	s := memory.NewState(func(ref *types.FnInstanceRef) {})
+	t.Cleanup(func() { s.Close() })
+
+	if err := s.Set(context.Background(), testFunction); err != nil {
+		t.Fatal(err)
+	}
+
+	var elector *leader.Elector
+	if isLeader {
+		elector = leader.NewElector(&leader.Config{}, s)
+	} else {
+		// The lock is never acquired, as the election isn't run
+		elector = leader.NewElector(&leader.Config{}, &testutil.Locker{State: s})
+	}
+
+	cfg := &Config{TargetConcurrency: 10, MaxInstances: 10, ScaleDownDelay: time.Minute, Interval: time.Minute}
+	return NewAutoscaler(cfg, s, orch, testutil.NewTracker(), elector), s
+}
+
+// recordInstance registers an instance of the test function into the state, last invoked at the given date
+func recordInstance(t *testing.T, s state.State, fn *types.Function, instanceId string, lastInvocation time.Time) {
+	t.Helper()
+
+	ctx := context.Background()
+	record := &types.FnInstanceRecord{FunctionId: fn.Id, InstanceId: instanceId, FunctionName: fn.Name, Revision: fn.Revision}
+	if err := s.SetInstance(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetLastInvocation(ctx, record.Ref(), lastInvocation); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// recordedInstances returns the sorted identifiers of the instances registered into the state
+func recordedInstances(t *testing.T, s state.State) []string {
+	t.Helper()
+
+	records, err := s.ListInstances(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.InstanceId)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestScaleDownOnlyOnLeader(t *testing.T) {
+	orch := &testutil.Orchestrator{}
+	a, s := newTestAutoscaler(t, orch, false)
+
+	idle := time.Now().Add(-time.Hour)
+	recordInstance(t, s, testFunction, "instance-1", idle)
+	recordInstance(t, s, testFunction, "instance-2", idle)
+
+	a.scaleDown(context.Background())
+
+	if deleted := orch.Deleted(); len(deleted) != 0 {
+		t.Fatalf("deleted instances = %v, want none", deleted)
+	}
+}
+
+func TestScaleDownKeepsInstancesInvokedByAnotherController(t *testing.T) {
+	orch := &testutil.Orchestrator{}
+	a, s := newTestAutoscaler(t, orch, true)
+
+	// The busy instance is only known to be used through the state
+	idle := time.Now().Add(-time.Hour)
+	recordInstance(t, s, testFunction, "busy", time.Now())
+	recordInstance(t, s, testFunction, "idle-1", idle)
+	recordInstance(t, s, testFunction, "idle-2", idle.Add(time.Second))
+
+	a.scaleDown(context.Background())
+
+	if want := []string{"idle-1", "idle-2"}; !equal(orch.Deleted(), want) {
+		t.Fatalf("deleted instances = %v, want %v", orch.Deleted(), want)
+	}
+	if recorded, want := recordedInstances(t, s), []string{"busy"}; !equal(recorded, want) {
+		t.Fatalf("recorded instances = %v, want %v", recorded, want)
+	}
+}
+
+func TestScaleDownKeepsMinimumInstances(t *testing.T) {
+	orch := &testutil.Orchestrator{}
+	a, s := newTestAutoscaler(t, orch, true)
+
+	fn := *testFunction
+	fn.MinInstances = 2
+	if err := s.Set(context.Background(), &fn); err != nil {
+		t.Fatal(err)
+	}
+
+	idle := time.Now().Add(-time.Hour)
+	recordInstance(t, s, &fn, "oldest", idle)
+	recordInstance(t, s, &fn, "older", idle.Add(time.Second))
+	recordInstance(t, s, &fn, "old", idle.Add(2*time.Second))
+
+	a.scaleDown(context.Background())
+
+	if want := []string{"oldest"}; !equal(orch.Deleted(), want) {
+		t.Fatalf("deleted instances = %v, want %v", orch.Deleted(), want)
+	}
+}
+
+func TestAcquirePublishesInvocation(t *testing.T) {
+	instance := testutil.NewInstance(testFunction, "instance-1")
+	orch := &testutil.Orchestrator{Instances: []*types.FnInstance{instance}}
+	a, s := newTestAutoscaler(t, orch, false)
+
+	acquired, err := a.Acquire(context.Background(), testFunction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Release(acquired)
+
+	if _, err := s.GetLastInvocation(context.Background(), instance.Ref()); err != nil {
+		t.Fatalf("GetLastInvocation() = %v, want the invocation to be published", err)
+	}
+
+	// The leader doesn't track the instance, but knows it has just been invoked
+	recordInstance(t, s, testFunction, "idle", time.Now().Add(-time.Hour))
+	elected := NewAutoscaler(a.cfg, s, orch, a.tracker, leader.NewElector(&leader.Config{}, s))
+	elected.scaleDown(context.Background())
+
+	if want := []string{"idle"}; !equal(orch.Deleted(), want) {
+		t.Fatalf("deleted instances = %v, want %v", orch.Deleted(), want)
+	}
+}
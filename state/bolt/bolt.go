@@ -0,0 +1,406 @@
+package bolt
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/types"
+	log "github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// reapInterval is the interval at which the expired instances are looked for
+	reapInterval = time.Second
+	// maxRetryDelay is the maximum delay before an expired instance is notified again
+	maxRetryDelay = 5 * time.Minute
+)
+
+var (
+	// functionsBucket holds the function definitions, indexed by name
+	functionsBucket = []byte("functions")
+	// instancesBucket holds the instances expiry, indexed by instance reference. Only DeleteInstance
+	// removes an instance from it, so the expired instances are notified until they are torn down.
+	instancesBucket = []byte("instances")
+	// recordsBucket holds the instance records, indexed by instance reference
+	recordsBucket = []byte("records")
+	// invocationsBucket holds the date of the last invocation of the instances, indexed by instance reference
+	invocationsBucket = []byte("invocations")
+)
+
+// adapter is an implementation of the state.State interface
+type adapter struct {
+	db             *bolt.DB
+	expiryCallback state.FnExpiryCallback
+	reapInterval   time.Duration
+	retry          state.ExpiryRetry
+	// done is closed to stop the reaper, which closes stopped once it returned
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+// Config hold the configuration about the BoltDB state adapter
+type Config struct {
+	// Path is the path of the database file, created if it doesn't exist
+	Path string `yaml:"path"`
+}
+
+var _ state.State = (*adapter)(nil)
+
+// NewState initializes a new state adapter for BoltDB based on the given configuration.
+// An error could be returned if any errors happens during the adapter initialization.
+func NewState(cfg *Config, expiryCallback state.FnExpiryCallback) (state.State, error) {
+	log.Debugf("Bootstrapping BoltDB state adapter with options: %#v", cfg)
+
+	a, err := newAdapter(cfg, expiryCallback, reapInterval, state.ExpiryRetry{Min: reapInterval, Max: maxRetryDelay})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("State engine 'bolt' successfully initialized")
+	return a, nil
+}
+
+// newAdapter opens the database and starts the reaper. An expired instance is notified
+// again with the given retry backoff, until it is deleted from the state.
+func newAdapter(cfg *Config, expiryCallback state.FnExpiryCallback, reapInterval time.Duration, retry state.ExpiryRetry) (*adapter, error) {
+	// The timeout prevents to wait forever if another process holds the database lock
+	db, err := bolt.Open(cfg.Path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		log.Errorf("Failed to open BoltDB database: %v", err)
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{functionsBucket, instancesBucket, recordsBucket, invocationsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	a := &adapter{
+		db:             db,
+		expiryCallback: expiryCallback,
+		reapInterval:   reapInterval,
+		retry:          retry,
+		done:           make(chan struct{}),
+		stopped:        make(chan struct{}),
+	}
+
+	// The instances that expired while the controller was stopped are reaped on the first tick
+	go a.reap()
+
+	return a, nil
+}
+
+func (a *adapter) Get(ctx context.Context, key string) (*types.Function, error) {
+	log.Tracef("state/bolt: retrieving value for key '%s'", key)
+
+	var fn *types.Function
+	err := a.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(functionsBucket).Get([]byte(key))
+		if v == nil {
+			return state.ErrKeyNotFound
+		}
+
+		fn = &types.Function{}
+		return json.Unmarshal(v, fn)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fn, nil
+}
+
+func (a *adapter) List(ctx context.Context) ([]*types.Function, error) {
+	functions := []*types.Function{}
+	err := a.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(functionsBucket).ForEach(func(k, v []byte) error {
+			fn := &types.Function{}
+			if err := json.Unmarshal(v, fn); err != nil {
+				return err
+			}
+			functions = append(functions, fn)
+			return nil
+		})
+	})
+	return functions, err
+}
+
+func (a *adapter) Set(ctx context.Context, fn *types.Function) error {
+	log.Tracef("state/bolt: setting value '%+v' for key '%s'", fn, fn.Name)
+	return a.db.Update(func(tx *bolt.Tx) error {
+		return putFunction(tx, fn)
+	})
+}
+
+func (a *adapter) SetMultiple(ctx context.Context, functions []*types.Function) []error {
+	// All the functions are written in a single transaction
+	err := a.db.Update(func(tx *bolt.Tx) error {
+		for _, fn := range functions {
+			if err := putFunction(tx, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return []error{err}
+	}
+	return nil
+}
+
+func (a *adapter) SetWithExpiry(ctx context.Context, ref *types.FnInstanceRef, expiry time.Duration) error {
+	key := ref.String()
+	log.Debugf("Set expiration of %v for key %s", expiry, key)
+
+	return a.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(instancesBucket).Put([]byte(key), encodeExpiry(time.Now().Add(expiry), 0))
+	})
+}
+
+func (a *adapter) GetExpiry(ctx context.Context, ref *types.FnInstanceRef) (time.Time, error) {
+	var at time.Time
+	err := a.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(instancesBucket).Get([]byte(ref.String()))
+		if v == nil {
+			return state.ErrKeyNotFound
+		}
+
+		// An instance already notified as expired isn't warm anymore, even if it is still being torn down
+		var attempts uint32
+		if at, attempts = decodeExpiry(v); attempts > 0 {
+			return state.ErrKeyNotFound
+		}
+		return nil
+	})
+	return at, err
+}
+
+func (a *adapter) SetLastInvocation(ctx context.Context, ref *types.FnInstanceRef, at time.Time) error {
+	return a.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(invocationsBucket).Put([]byte(ref.String()), encodeTime(at))
+	})
+}
+
+func (a *adapter) GetLastInvocation(ctx context.Context, ref *types.FnInstanceRef) (time.Time, error) {
+	var at time.Time
+	err := a.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(invocationsBucket).Get([]byte(ref.String()))
+		if v == nil {
+			return state.ErrKeyNotFound
+		}
+
+		at = decodeTime(v)
+		return nil
+	})
+	return at, err
+}
+
+func (a *adapter) SetInstance(ctx context.Context, record *types.FnInstanceRecord) error {
+	v, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return a.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(recordsBucket).Put([]byte(record.Ref().String()), v)
+	})
+}
+
+func (a *adapter) ListInstances(ctx context.Context) ([]*types.FnInstanceRecord, error) {
+	var records []*types.FnInstanceRecord
+	err := a.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(recordsBucket).ForEach(func(k, v []byte) error {
+			record := &types.FnInstanceRecord{}
+			if err := json.Unmarshal(v, record); err != nil {
+				return err
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+	return records, err
+}
+
+func (a *adapter) DeleteInstance(ctx context.Context, ref *types.FnInstanceRef) error {
+	key := []byte(ref.String())
+	log.Tracef("state/bolt: deleting instance '%s'", key)
+	return a.db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{recordsBucket, instancesBucket, invocationsBucket} {
+			if err := tx.Bucket(bucket).Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (a *adapter) Ping(ctx context.Context) error {
+	// The database is embedded, it is reachable as long as it is open
+	return a.db.View(func(tx *bolt.Tx) error { return nil })
+}
+
+func (a *adapter) Delete(ctx context.Context, key string) error {
+	log.Tracef("state/bolt: deleting key '%s'", key)
+	return a.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(functionsBucket).Delete([]byte(key))
+	})
+}
+
+func (a *adapter) Close() error {
+	var err error
+	a.closeOnce.Do(func() {
+		close(a.done)
+		<-a.stopped
+		err = a.db.Close()
+	})
+	return err
+}
+
+// reap periodically notifies the expiry callback of the expired instances. They are kept until they are
+// deleted from the state, and notified again with a backoff in case the callback failed to tear them down.
+func (a *adapter) reap() {
+	defer close(a.stopped)
+
+	ticker := time.NewTicker(a.reapInterval)
+	defer ticker.Stop()
+
+	for {
+		var now time.Time
+		select {
+		case <-a.done:
+			return
+		case now = <-ticker.C:
+		}
+
+		// The expired keys are looked for in a read-only transaction first,
+		// so an idle controller doesn't write to the file every second
+		keys, err := a.findExpired(now)
+		if err != nil {
+			log.Errorf("failed to reap expired instances: %v", err)
+			continue
+		}
+
+		if len(keys) == 0 {
+			continue
+		}
+
+		expired, err := a.claimExpired(keys, now)
+		if err != nil {
+			log.Errorf("failed to reap expired instances: %v", err)
+			continue
+		}
+
+		// The callback is called outside of the transaction, as it may use the state
+		for _, ref := range expired {
+			log.Tracef("Key %s has expired", ref)
+			a.expiryCallback(ref)
+		}
+	}
+}
+
+// findExpired is a helper function to look for the keys of the instances expired at the given date
+func (a *adapter) findExpired(now time.Time) ([][]byte, error) {
+	var keys [][]byte
+	err := a.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(instancesBucket).ForEach(func(k, v []byte) error {
+			if at, _ := decodeExpiry(v); !at.After(now) {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+	})
+	return keys, err
+}
+
+// claimExpired is a helper function to push back the expiry of the given instances with the retry backoff,
+// and return the ones to notify. The instances whose expiry was extended since they were found are skipped.
+func (a *adapter) claimExpired(keys [][]byte, now time.Time) ([]*types.FnInstanceRef, error) {
+	var expired []*types.FnInstanceRef
+	err := a.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(instancesBucket)
+		for _, k := range keys {
+			v := b.Get(k)
+			if v == nil {
+				continue
+			}
+			at, attempts := decodeExpiry(v)
+			if at.After(now) {
+				continue
+			}
+
+			ref, err := types.ParseFnInstanceRef(string(k))
+			if err != nil {
+				// The key can't be notified, so it would never be deleted
+				log.Debugf("Ignoring expired key: %v", err)
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+				continue
+			}
+
+			attempts++
+			if err := b.Put(k, encodeExpiry(now.Add(a.retry.Delay(int(attempts))), attempts)); err != nil {
+				return err
+			}
+			expired = append(expired, ref)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// putFunction is a helper function to write a function into the given transaction
+func putFunction(tx *bolt.Tx, fn *types.Function) error {
+	v, err := json.Marshal(fn)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(functionsBucket).Put([]byte(fn.Name), v)
+}
+
+// encodeTime is a helper function to encode a date as a big endian unix timestamp in nanoseconds
+func encodeTime(t time.Time) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(t.UnixNano()))
+	return b
+}
+
+// decodeTime is a helper function to decode a date encoded with encodeTime
+func decodeTime(b []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b)))
+}
+
+// encodeExpiry is a helper function to encode the expiry of an instance, followed by the number of times it
+// was notified as expired. The number is omitted until the instance expires, as in the previous format.
+func encodeExpiry(at time.Time, attempts uint32) []byte {
+	b := encodeTime(at)
+	if attempts > 0 {
+		b = binary.BigEndian.AppendUint32(b, attempts)
+	}
+	return b
+}
+
+// decodeExpiry is a helper function to decode an expiry encoded with encodeExpiry
+func decodeExpiry(b []byte) (time.Time, uint32) {
+	var attempts uint32
+	if len(b) >= 12 {
+		attempts = binary.BigEndian.Uint32(b[8:12])
+	}
+	return decodeTime(b), attempts
+}
@@ -0,0 +1,206 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/morty-faas/controller/internal/testutil"
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/types"
+)
+
+var testRetry = state.ExpiryRetry{Min: 20 * time.Millisecond, Max: 40 * time.Millisecond}
+
+// newTestAdapter opens the database at the given path, reaping the expired instances every few milliseconds
+func newTestAdapter(t *testing.T, path string) (*adapter, testutil.Expirations) {
+	t.Helper()
+
+	expired := testutil.NewExpirations()
+	a, err := newAdapter(&Config{Path: path}, expired.Callback, 5*time.Millisecond, testRetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Close() })
+	return a, expired
+}
+
+func testPath(t *testing.T) string {
+	return filepath.Join(t.TempDir(), "state.db")
+}
+
+func TestFunctions(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestAdapter(t, testPath(t))
+
+	fn := &types.Function{Id: "weatho-r1", Name: "weatho", Revision: 1, ImageURL: "morty/weatho:v1"}
+	if err := a.Set(ctx, fn); err != nil {
+		t.Fatal(err)
+	}
+	if errs := a.SetMultiple(ctx, []*types.Function{{Name: "hello"}, {Name: "world"}}); errs != nil {
+		t.Fatal(errs)
+	}
+
+	got, err := a.Get(ctx, "weatho")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Id != fn.Id || got.ImageURL != fn.ImageURL || got.Revision != fn.Revision {
+		t.Fatalf("Get() = %+v, want %+v", got, fn)
+	}
+
+	functions, err := a.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(functions) != 3 {
+		t.Fatalf("List() returned %d functions, want 3", len(functions))
+	}
+
+	if err := a.Delete(ctx, "weatho"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Get(ctx, "weatho"); !errors.Is(err, state.ErrKeyNotFound) {
+		t.Fatalf("Get() = %v, want %v", err, state.ErrKeyNotFound)
+	}
+}
+
+func TestDeleteInstance(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestAdapter(t, testPath(t))
+
+	record := &types.FnInstanceRecord{FunctionId: "weatho-r1", InstanceId: "instance-1", FunctionName: "weatho", Revision: 1}
+	ref := record.Ref()
+	if err := a.SetInstance(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetWithExpiry(ctx, ref, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetLastInvocation(ctx, ref, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.DeleteInstance(ctx, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := a.ListInstances(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("ListInstances() = %+v, want none", records)
+	}
+	if _, err := a.GetExpiry(ctx, ref); !errors.Is(err, state.ErrKeyNotFound) {
+		t.Fatalf("GetExpiry() = %v, want %v", err, state.ErrKeyNotFound)
+	}
+	if _, err := a.GetLastInvocation(ctx, ref); !errors.Is(err, state.ErrKeyNotFound) {
+		t.Fatalf("GetLastInvocation() = %v, want %v", err, state.ErrKeyNotFound)
+	}
+}
+
+func TestReapRetriesUntilInstanceDeleted(t *testing.T) {
+	ctx := context.Background()
+	a, expired := newTestAdapter(t, testPath(t))
+
+	ref := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-1"}
+	if err := a.SetWithExpiry(ctx, ref, 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetExpiry(ctx, ref); err != nil {
+		t.Fatalf("GetExpiry() = %v, want the instance to be warm", err)
+	}
+
+	// The callback didn't delete the instance, so it is notified again after the backoff
+	expired.Wait(t, ref, time.Second)
+	if _, err := a.GetExpiry(ctx, ref); !errors.Is(err, state.ErrKeyNotFound) {
+		t.Fatalf("GetExpiry() = %v, want %v once expired", err, state.ErrKeyNotFound)
+	}
+	expired.Wait(t, ref, time.Second)
+
+	if err := a.DeleteInstance(ctx, ref); err != nil {
+		t.Fatal(err)
+	}
+	expired.None(t, 2*testRetry.Max)
+}
+
+func TestReapAfterReopen(t *testing.T) {
+	ctx := context.Background()
+	path := testPath(t)
+
+	a, _ := newTestAdapter(t, path)
+	ref := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-1"}
+	if err := a.Set(ctx, &types.Function{Name: "weatho"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetWithExpiry(ctx, ref, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The instance expires while no controller is running
+	time.Sleep(100 * time.Millisecond)
+
+	reopened, expired := newTestAdapter(t, path)
+	expired.Wait(t, ref, time.Second)
+
+	if _, err := reopened.Get(ctx, "weatho"); err != nil {
+		t.Fatalf("Get() = %v, want the function to be persisted", err)
+	}
+}
+
+func TestReapSkipsExpiryExtendedAfterScan(t *testing.T) {
+	ctx := context.Background()
+	// The reaper ticks too rarely to interfere with the test
+	a, err := newAdapter(&Config{Path: testPath(t)}, func(ref *types.FnInstanceRef) {}, time.Hour, testRetry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Close() })
+
+	ref := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-1"}
+	if err := a.SetWithExpiry(ctx, ref, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	keys, err := a.findExpired(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("findExpired() = %q, want %s", keys, ref)
+	}
+
+	// The instance is invoked between the scan and the claim
+	if err := a.SetWithExpiry(ctx, ref, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	expired, err := a.claimExpired(keys, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 0 {
+		t.Fatalf("claimExpired() = %v, want none", expired)
+	}
+	if _, err := a.GetExpiry(ctx, ref); err != nil {
+		t.Fatalf("GetExpiry() = %v, want the instance to be warm", err)
+	}
+}
+
+func TestCloseTwice(t *testing.T) {
+	a, _ := newTestAdapter(t, testPath(t))
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,368 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/types"
+	log "github.com/sirupsen/logrus"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// functionsPrefix is the prefix of the keys holding the function definitions
+	functionsPrefix = "/morty/functions/"
+	// instancesPrefix is the prefix of the keys holding the instances expiry, attached to a lease
+	instancesPrefix = "/morty/instances/"
+	// expiringPrefix is the prefix of the keys marking the instances with an expiry, until they are deleted
+	expiringPrefix = "/morty/expiring/"
+	// recordsPrefix is the prefix of the keys holding the instance records
+	recordsPrefix = "/morty/records/"
+	// invocationsPrefix is the prefix of the keys holding the date of the last invocation of the instances
+	invocationsPrefix = "/morty/invocations/"
+	// locksPrefix is the prefix of the keys holding the owner of a lock, attached to a lease
+	locksPrefix = "/morty/locks/"
+)
+
+// adapter is an implementation of the state.State interface
+type adapter struct {
+	client         *clientv3.Client
+	expiryCallback state.FnExpiryCallback
+	// cancel stops the expiry watch and sweep, wg waits for them to return
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// sweepNow requests a sweep of the expired instances
+	sweepNow chan struct{}
+}
+
+// Config hold the configuration about the etcd state adapter
+type Config struct {
+	Endpoints   []string      `yaml:"endpoints"`
+	Username    string        `yaml:"username"`
+	Password    string        `yaml:"password"`
+	DialTimeout time.Duration `yaml:"dialTimeout"`
+}
+
+var (
+	_ state.State  = (*adapter)(nil)
+	_ state.Locker = (*adapter)(nil)
+)
+
+// NewState initializes a new state adapter for etcd based on the given configuration.
+// An error could be returned if any errors happens during the adapter initialization.
+func NewState(cfg *Config, expiryCallback state.FnExpiryCallback) (state.State, error) {
+	log.Debugf("Bootstrapping etcd state adapter with endpoints: %v", cfg.Endpoints)
+
+	dialTimeout := cfg.DialTimeout
+	if dialTimeout == 0 {
+		dialTimeout = 5 * time.Second
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   cfg.Endpoints,
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+		DialTimeout: dialTimeout,
+	})
+	if err != nil {
+		log.Errorf("Failed to connect to etcd: %v", err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &adapter{
+		client:         client,
+		expiryCallback: expiryCallback,
+		cancel:         cancel,
+		sweepNow:       make(chan struct{}, 1),
+	}
+
+	// The instances keys are attached to a lease, so they are deleted by etcd once
+	// the lease expires. We watch these deletions to handle the instances expiration,
+	// and sweep the instances that expired while no controller was watching.
+	a.wg.Add(2)
+	go a.watch(ctx)
+	go a.sweep(ctx)
+
+	log.Info("State engine 'etcd' successfully initialized")
+	return a, nil
+}
+
+func (a *adapter) Get(ctx context.Context, key string) (*types.Function, error) {
+	log.Tracef("state/etcd: retrieving value for key '%s'", key)
+	res, err := a.client.Get(ctx, functionsPrefix+key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Kvs) == 0 {
+		return nil, state.ErrKeyNotFound
+	}
+
+	fn := &types.Function{}
+	if err := json.Unmarshal(res.Kvs[0].Value, fn); err != nil {
+		return nil, err
+	}
+	return fn, nil
+}
+
+func (a *adapter) List(ctx context.Context) ([]*types.Function, error) {
+	res, err := a.client.Get(ctx, functionsPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	functions := make([]*types.Function, 0, len(res.Kvs))
+	for _, kv := range res.Kvs {
+		fn := &types.Function{}
+		if err := json.Unmarshal(kv.Value, fn); err != nil {
+			return nil, err
+		}
+		functions = append(functions, fn)
+	}
+	return functions, nil
+}
+
+func (a *adapter) Set(ctx context.Context, fn *types.Function) error {
+	log.Tracef("state/etcd: setting value '%+v' for key '%s'", fn, fn.Name)
+	value, err := json.Marshal(fn)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.client.Put(ctx, functionsPrefix+fn.Name, string(value))
+	return err
+}
+
+func (a *adapter) SetMultiple(ctx context.Context, functions []*types.Function) []error {
+	errors := []error{}
+	for _, fn := range functions {
+		if err := a.Set(ctx, fn); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return errors
+}
+
+func (a *adapter) SetWithExpiry(ctx context.Context, ref *types.FnInstanceRef, expiry time.Duration) error {
+	key := ref.String()
+	log.Debugf("Set expiration of %v for key %s", expiry, key)
+
+	// The instance key holds the TTL of its lease
+	ttl := leaseTTL(expiry)
+	value := strconv.FormatInt(ttl, 10)
+
+	res, err := a.client.Get(ctx, instancesPrefix+key)
+	if err != nil {
+		return err
+	}
+
+	// The lease of the instance is renewed as long as its TTL doesn't change,
+	// so the invocations don't leave a live lease behind them
+	var previous clientv3.LeaseID
+	if len(res.Kvs) > 0 && res.Kvs[0].Lease != 0 {
+		previous = clientv3.LeaseID(res.Kvs[0].Lease)
+		if string(res.Kvs[0].Value) == value {
+			_, err := a.client.KeepAliveOnce(ctx, previous)
+			if !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+				return err
+			}
+			// The lease expired in the meantime, a new one is granted
+			previous = 0
+		}
+	}
+
+	lease, err := a.client.Grant(ctx, ttl)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.client.Txn(ctx).Then(
+		clientv3.OpPut(instancesPrefix+key, value, clientv3.WithLease(lease.ID)),
+		clientv3.OpPut(expiringPrefix+key, ""),
+	).Commit()
+	if err != nil {
+		return err
+	}
+
+	// The key isn't attached to the previous lease anymore, so revoking it doesn't delete the key
+	if previous != 0 {
+		if _, err := a.client.Revoke(ctx, previous); err != nil && !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+			log.Warnf("Failed to revoke the previous lease of instance %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+func (a *adapter) GetExpiry(ctx context.Context, ref *types.FnInstanceRef) (time.Time, error) {
+	res, err := a.client.Get(ctx, instancesPrefix+ref.String())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if len(res.Kvs) == 0 || res.Kvs[0].Lease == 0 {
+		return time.Time{}, state.ErrKeyNotFound
+	}
+
+	lease, err := a.client.TimeToLive(ctx, clientv3.LeaseID(res.Kvs[0].Lease))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// A negative TTL means that the lease has already expired
+	if lease.TTL < 0 {
+		return time.Time{}, state.ErrKeyNotFound
+	}
+
+	return time.Now().Add(time.Duration(lease.TTL) * time.Second), nil
+}
+
+func (a *adapter) SetLastInvocation(ctx context.Context, ref *types.FnInstanceRef, at time.Time) error {
+	_, err := a.client.Put(ctx, invocationsPrefix+ref.String(), at.Format(time.RFC3339Nano))
+	return err
+}
+
+func (a *adapter) GetLastInvocation(ctx context.Context, ref *types.FnInstanceRef) (time.Time, error) {
+	res, err := a.client.Get(ctx, invocationsPrefix+ref.String())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if len(res.Kvs) == 0 {
+		return time.Time{}, state.ErrKeyNotFound
+	}
+	return time.Parse(time.RFC3339Nano, string(res.Kvs[0].Value))
+}
+
+func (a *adapter) SetInstance(ctx context.Context, record *types.FnInstanceRecord) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.client.Put(ctx, recordsPrefix+record.Ref().String(), string(value))
+	return err
+}
+
+func (a *adapter) ListInstances(ctx context.Context) ([]*types.FnInstanceRecord, error) {
+	res, err := a.client.Get(ctx, recordsPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*types.FnInstanceRecord, 0, len(res.Kvs))
+	for _, kv := range res.Kvs {
+		record := &types.FnInstanceRecord{}
+		if err := json.Unmarshal(kv.Value, record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (a *adapter) DeleteInstance(ctx context.Context, ref *types.FnInstanceRef) error {
+	key := ref.String()
+	log.Tracef("state/etcd: deleting instance '%s'", key)
+
+	// Deleting the expiry key also triggers the expiry callback, which must thus be idempotent
+	_, err := a.client.Txn(ctx).Then(
+		clientv3.OpDelete(recordsPrefix+key),
+		clientv3.OpDelete(instancesPrefix+key),
+		clientv3.OpDelete(expiringPrefix+key),
+		clientv3.OpDelete(invocationsPrefix+key),
+	).Commit()
+	return err
+}
+
+func (a *adapter) Ping(ctx context.Context) error {
+	// A linearizable read fails if the cluster doesn't have a leader
+	_, err := a.client.Get(ctx, "health")
+	return err
+}
+
+func (a *adapter) Delete(ctx context.Context, key string) error {
+	log.Tracef("state/etcd: deleting key '%s'", key)
+	_, err := a.client.Delete(ctx, functionsPrefix+key)
+	return err
+}
+
+func (a *adapter) Close() error {
+	a.cancel()
+	a.wg.Wait()
+	return a.client.Close()
+}
+
+func (a *adapter) AcquireLock(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	key := locksPrefix + name
+
+	res, err := a.client.Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	// The lock is only written if it didn't change since we read it, so two
+	// controllers can't both acquire it, or renew it after it has been taken over
+	var cmp clientv3.Cmp
+	switch {
+	case len(res.Kvs) == 0:
+		cmp = clientv3.Compare(clientv3.CreateRevision(key), "=", 0)
+	case string(res.Kvs[0].Value) == holder:
+		// The lease attached to the lock is renewed rather than replaced
+		_, err := a.client.KeepAliveOnce(ctx, clientv3.LeaseID(res.Kvs[0].Lease))
+		if !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+			return err == nil, err
+		}
+		// The lease expired in the meantime along with the lock, so it can be acquired again
+		cmp = clientv3.Compare(clientv3.CreateRevision(key), "=", 0)
+	default:
+		return false, nil
+	}
+
+	lease, err := a.client.Grant(ctx, leaseTTL(ttl))
+	if err != nil {
+		return false, err
+	}
+
+	txn, err := a.client.Txn(ctx).If(cmp).Then(clientv3.OpPut(key, holder, clientv3.WithLease(lease.ID))).Commit()
+	if err != nil {
+		return false, err
+	}
+	return txn.Succeeded, nil
+}
+
+func (a *adapter) ReleaseLock(ctx context.Context, name, holder string) error {
+	key := locksPrefix + name
+	txn, err := a.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.Value(key), "=", holder)).
+		Then(clientv3.OpGet(key), clientv3.OpDelete(key)).
+		Commit()
+	if err != nil || !txn.Succeeded {
+		return err
+	}
+
+	// The lease of the lock isn't needed anymore
+	kvs := txn.Responses[0].GetResponseRange().Kvs
+	if len(kvs) > 0 && kvs[0].Lease != 0 {
+		if _, err := a.client.Revoke(ctx, clientv3.LeaseID(kvs[0].Lease)); err != nil && !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// leaseTTL is a helper function to convert a duration into a lease TTL.
+// etcd leases have a granularity of one second.
+func leaseTTL(d time.Duration) int64 {
+	ttl := int64(d.Round(time.Second) / time.Second)
+	if ttl < 1 {
+		ttl = 1
+	}
+	return ttl
+}
@@ -0,0 +1,382 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/morty-faas/controller/internal/testutil"
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/types"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+// startServer starts an embedded etcd server for the duration of the test, and returns its client URL
+func startServer(t *testing.T) string {
+	t.Helper()
+
+	cfg := newServerConfig(t)
+	runServer(t, cfg)
+	return cfg.AdvertiseClientUrls[0].String()
+}
+
+// newServerConfig is a helper function to configure a single node cluster listening on local addresses
+func newServerConfig(t *testing.T) *embed.Config {
+	t.Helper()
+
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+
+	clientURL, peerURL := freeURL(t), freeURL(t)
+	cfg.ListenClientUrls, cfg.AdvertiseClientUrls = []url.URL{clientURL}, []url.URL{clientURL}
+	cfg.ListenPeerUrls, cfg.AdvertisePeerUrls = []url.URL{peerURL}, []url.URL{peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	return cfg
+}
+
+// runServer is a helper function to start a server with the given configuration, and wait for it to be ready.
+// It returns a function to stop the server before the end of the test.
+func runServer(t *testing.T, cfg *embed.Config) (stop func()) {
+	t.Helper()
+
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var once sync.Once
+	stop = func() { once.Do(e.Close) }
+	t.Cleanup(stop)
+
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("etcd server didn't start in time")
+	}
+
+	return stop
+}
+
+// freeURL is a helper function to find an available local address
+func freeURL(t *testing.T) url.URL {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	return url.URL{Scheme: "http", Host: l.Addr().String()}
+}
+
+// newTestState starts an embedded etcd server and initializes a state adapter connected to it.
+// The expired instances are collected by the returned Expirations.
+func newTestState(t *testing.T) (*adapter, testutil.Expirations) {
+	t.Helper()
+	return newTestStateOn(t, startServer(t))
+}
+
+func newTestStateOn(t *testing.T, endpoint string) (*adapter, testutil.Expirations) {
+	t.Helper()
+
+	expired := testutil.NewExpirations()
+	s, err := NewState(&Config{Endpoints: []string{endpoint}}, expired.Callback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s.(*adapter), expired
+}
+
+// countLeases is a helper function to count the leases alive in the cluster
+func countLeases(t *testing.T, a *adapter) int {
+	t.Helper()
+
+	res, err := a.client.Leases(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(res.Leases)
+}
+
+func TestFunctions(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestState(t)
+
+	for _, name := range []string{"weatho", "hello"} {
+		fn := (&types.Function{Name: name}).NewRevision("registry/" + name + ":1")
+		fn.Deployed(name + "-r1")
+		if err := a.Set(ctx, fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fn, err := a.Get(ctx, "weatho")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fn.Id != "weatho-r1" || fn.ImageURL != "registry/weatho:1" || len(fn.Revisions) != 1 {
+		t.Fatalf("Get() = %+v, want the function as it has been set", fn)
+	}
+
+	functions, err := a.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(functions) != 2 {
+		t.Fatalf("List() returned %d functions, want 2", len(functions))
+	}
+
+	if err := a.Delete(ctx, "weatho"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Get(ctx, "weatho"); !errors.Is(err, state.ErrKeyNotFound) {
+		t.Fatalf("Get() after Delete() = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestInstances(t *testing.T) {
+	ctx := context.Background()
+	a, expired := newTestState(t)
+
+	ref := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-1"}
+	record := &types.FnInstanceRecord{FunctionId: ref.FunctionId, InstanceId: ref.InstanceId, FunctionName: "weatho", Revision: 1}
+	if err := a.SetInstance(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetWithExpiry(ctx, ref, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := a.ListInstances(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || *records[0] != *record {
+		t.Fatalf("ListInstances() = %+v, want [%+v]", records, record)
+	}
+
+	warmUntil, err := a.GetExpiry(ctx, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining := time.Until(warmUntil); remaining < 59*time.Minute || remaining > time.Hour {
+		t.Fatalf("GetExpiry() is in %v, want about 1h", remaining)
+	}
+
+	invokedAt := time.Now().Round(0)
+	if err := a.SetLastInvocation(ctx, ref, invokedAt); err != nil {
+		t.Fatal(err)
+	}
+	if at, err := a.GetLastInvocation(ctx, ref); err != nil || !at.Equal(invokedAt) {
+		t.Fatalf("GetLastInvocation() = %v, %v, want %v", at, err, invokedAt)
+	}
+
+	if err := a.DeleteInstance(ctx, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err = a.ListInstances(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("ListInstances() after DeleteInstance() = %+v, want none", records)
+	}
+	if _, err := a.GetExpiry(ctx, ref); !errors.Is(err, state.ErrKeyNotFound) {
+		t.Fatalf("GetExpiry() after DeleteInstance() = %v, want ErrKeyNotFound", err)
+	}
+	if _, err := a.GetLastInvocation(ctx, ref); !errors.Is(err, state.ErrKeyNotFound) {
+		t.Fatalf("GetLastInvocation() after DeleteInstance() = %v, want ErrKeyNotFound", err)
+	}
+
+	// Deleting the expiry key is notified as well, the callback must be idempotent
+	expired.Wait(t, ref, 15*time.Second)
+}
+
+func TestInstanceExpiry(t *testing.T) {
+	a, expired := newTestState(t)
+
+	ref := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-1"}
+	if err := a.SetWithExpiry(context.Background(), ref, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	expired.Wait(t, ref, 15*time.Second)
+}
+
+func TestSetWithExpiryReusesLease(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestState(t)
+
+	ref := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-1"}
+	for i := 0; i < 5; i++ {
+		if err := a.SetWithExpiry(ctx, ref, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := countLeases(t, a); n != 1 {
+		t.Fatalf("%d leases alive after renewing the expiry, want 1", n)
+	}
+
+	// A different TTL replaces the lease, and the previous one is revoked
+	if err := a.SetWithExpiry(ctx, ref, 2*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if n := countLeases(t, a); n != 1 {
+		t.Fatalf("%d leases alive after changing the expiry, want 1", n)
+	}
+
+	warmUntil, err := a.GetExpiry(ctx, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining := time.Until(warmUntil); remaining < 119*time.Minute {
+		t.Fatalf("GetExpiry() is in %v, want about 2h", remaining)
+	}
+}
+
+func TestSweepExpiredWithoutWatch(t *testing.T) {
+	ctx := context.Background()
+	endpoint := startServer(t)
+
+	client, err := clientv3.New(clientv3.Config{Endpoints: []string{endpoint}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// The instance expired while no controller was watching, only its marker is left
+	ref := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-1"}
+	if _, err := client.Put(ctx, expiringPrefix+ref.String(), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	_, expired := newTestStateOn(t, endpoint)
+	expired.Wait(t, ref, 15*time.Second)
+}
+
+func TestReceiveResumesAfterCompaction(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestState(t)
+
+	var res *clientv3.PutResponse
+	for i := 0; i < 3; i++ {
+		var err error
+		if res, err = a.client.Put(ctx, "/morty/test", fmt.Sprint(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := a.client.Compact(ctx, res.Header.Revision); err != nil {
+		t.Fatal(err)
+	}
+
+	// The events following the last handled revision have been compacted, so the watch
+	// fails and resumes from the compaction revision
+	revision := int64(1)
+	if _, err := a.receive(ctx, &revision); err == nil {
+		t.Fatal("receive() returned without error, want ErrCompacted")
+	}
+	if revision != res.Header.Revision-1 {
+		t.Fatalf("revision = %d, want %d", revision, res.Header.Revision-1)
+	}
+}
+
+func TestInstanceExpiryAfterServerRestart(t *testing.T) {
+	cfg := newServerConfig(t)
+	stop := runServer(t, cfg)
+
+	a, expired := newTestStateOn(t, cfg.AdvertiseClientUrls[0].String())
+
+	stop()
+	runServer(t, cfg)
+
+	ref := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-1"}
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		err := a.SetWithExpiry(context.Background(), ref, time.Second)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("etcd server didn't restart in time: %v", err)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	expired.Wait(t, ref, 15*time.Second)
+}
+
+func TestAcquireLockContention(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestState(t)
+
+	acquire := func(holder string, want bool) {
+		t.Helper()
+		acquired, err := a.AcquireLock(ctx, "leader", holder, 10*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if acquired != want {
+			t.Fatalf("AcquireLock(%s) = %v, want %v", holder, acquired, want)
+		}
+	}
+
+	acquire("controller-a", true)
+	acquire("controller-b", false)
+
+	// The holder renews its lease, without granting a new one
+	acquire("controller-a", true)
+	if n := countLeases(t, a); n != 1 {
+		t.Fatalf("%d leases alive after renewing the lock, want 1", n)
+	}
+
+	// Only the holder can release the lock
+	if err := a.ReleaseLock(ctx, "leader", "controller-b"); err != nil {
+		t.Fatal(err)
+	}
+	acquire("controller-b", false)
+
+	if err := a.ReleaseLock(ctx, "leader", "controller-a"); err != nil {
+		t.Fatal(err)
+	}
+	if n := countLeases(t, a); n != 0 {
+		t.Fatalf("%d leases alive after releasing the lock, want 0", n)
+	}
+	acquire("controller-b", true)
+}
+
+func TestAcquireLockExpires(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestState(t)
+
+	if acquired, err := a.AcquireLock(ctx, "leader", "controller-a", time.Second); err != nil || !acquired {
+		t.Fatalf("AcquireLock() = %v, %v, want true", acquired, err)
+	}
+
+	// Once the lease of the holder expires, another controller can take over
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		acquired, err := a.AcquireLock(ctx, "leader", "controller-b", time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if acquired {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(fmt.Errorf("lock wasn't released after the lease expired"))
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
@@ -0,0 +1,170 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/morty-faas/controller/types"
+	log "github.com/sirupsen/logrus"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// minRewatchBackoff and maxRewatchBackoff bound the delay between two watch attempts
+	minRewatchBackoff = 100 * time.Millisecond
+	maxRewatchBackoff = 30 * time.Second
+	// sweepInterval is the interval at which the instances whose key expired without notification are looked for
+	sweepInterval = time.Minute
+)
+
+var errWatchClosed = errors.New("watch channel closed")
+
+// watch handles the deletions of the instance keys, which happen once their lease expires. If the
+// watch fails, e.g. because the revision it resumes from has been compacted, it is created again
+// from the last handled revision with an exponential backoff, until the context is done.
+func (a *adapter) watch(ctx context.Context) {
+	defer a.wg.Done()
+
+	// revision is the last revision whose events have been handled, 0 to watch from the current one
+	var revision int64
+	backoff := minRewatchBackoff
+	for {
+		progressed, err := a.receive(ctx, &revision)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if progressed {
+			backoff = minRewatchBackoff
+		}
+		log.Warnf("Lost the watch on the etcd expiry events, retrying in %v: %v", backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > maxRewatchBackoff {
+			backoff = maxRewatchBackoff
+		}
+
+		// Keys may have expired without being watched, e.g. if the events were compacted
+		a.triggerSweep()
+	}
+}
+
+// receive handles the expiration events from the given revision until the watch fails or the context
+// is done. It reports whether any event has been handled, and keeps the revision up to date.
+func (a *adapter) receive(ctx context.Context, revision *int64) (bool, error) {
+	opts := []clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithFilterPut()}
+	if *revision > 0 {
+		opts = append(opts, clientv3.WithRev(*revision+1))
+	}
+
+	// Without a leader, the watch would wait for one instead of failing
+	ctx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+
+	progressed := false
+	for res := range a.client.Watch(ctx, instancesPrefix, opts...) {
+		if err := res.Err(); err != nil {
+			if res.CompactRevision != 0 {
+				// The events before the compaction are lost, they are recovered by a sweep
+				*revision = res.CompactRevision - 1
+			}
+			return progressed, err
+		}
+
+		for _, ev := range res.Events {
+			a.handleExpiredKey(string(ev.Kv.Key))
+		}
+		*revision = res.Header.Revision
+		progressed = true
+	}
+
+	return progressed, errWatchClosed
+}
+
+// handleExpiredKey calls the expiry callback for the instance of the deleted key
+func (a *adapter) handleExpiredKey(key string) {
+	log.Tracef("Key %s has expired", key)
+
+	ref, err := types.ParseFnInstanceRef(strings.TrimPrefix(key, instancesPrefix))
+	if err != nil {
+		log.Debugf("Ignoring expiry event: %v", err)
+		return
+	}
+	a.expiryCallback(ref)
+}
+
+// sweep looks for the instances whose key expired without the expiry callback being called,
+// e.g. while no controller was watching. It runs on startup, on demand and periodically, until
+// the context is done.
+func (a *adapter) sweep(ctx context.Context) {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.sweepExpired(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("failed to sweep expired instances: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-a.sweepNow:
+		}
+	}
+}
+
+// triggerSweep requests a sweep, without waiting for it
+func (a *adapter) triggerSweep() {
+	select {
+	case a.sweepNow <- struct{}{}:
+	default:
+		// A sweep is already pending
+	}
+}
+
+// sweepExpired is a helper function to call the expiry callback for the expiring instances whose
+// key doesn't exist anymore. Their marker is removed by DeleteInstance.
+func (a *adapter) sweepExpired(ctx context.Context) error {
+	expiring, err := a.client.Get(ctx, expiringPrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil || len(expiring.Kvs) == 0 {
+		return err
+	}
+
+	// Both reads are done at the same revision, so they are consistent with each other
+	instances, err := a.client.Get(ctx, instancesPrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly(), clientv3.WithRev(expiring.Header.Revision))
+	if err != nil {
+		return err
+	}
+
+	alive := make(map[string]struct{}, len(instances.Kvs))
+	for _, kv := range instances.Kvs {
+		alive[strings.TrimPrefix(string(kv.Key), instancesPrefix)] = struct{}{}
+	}
+
+	for _, kv := range expiring.Kvs {
+		key := strings.TrimPrefix(string(kv.Key), expiringPrefix)
+		if _, exists := alive[key]; exists {
+			continue
+		}
+
+		ref, err := types.ParseFnInstanceRef(key)
+		if err != nil {
+			log.Debugf("Ignoring expiring instance: %v", err)
+			continue
+		}
+
+		log.Debugf("Instance %s expired without notification", ref)
+		a.expiryCallback(ref)
+	}
+	return nil
+}
@@ -2,7 +2,7 @@ package memory
 
 import (
 	"context"
-	"errors"
+	"sync"
 	"time"
 
 	"github.com/morty-faas/controller/state"
@@ -10,23 +10,74 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// reapInterval is the interval at which the expired instances are looked for
+	reapInterval = time.Second
+	// maxRetryDelay is the maximum delay before an expired instance is notified again
+	maxRetryDelay = 5 * time.Minute
+)
+
 // adapter is an implementation of the state.State interface
 type adapter struct {
-	store map[string]*types.Function
+	// The store is accessed concurrently by the API handlers and the reaper
+	mu        sync.RWMutex
+	store     map[string]*types.Function
+	instances map[string]*types.FnInstanceRecord
+	expiry    map[string]*instanceExpiry
+	// invocations contains the date of the last invocation of the instances, indexed by reference
+	invocations map[string]time.Time
+
+	expiryCallback state.FnExpiryCallback
+	reapInterval   time.Duration
+	retry          state.ExpiryRetry
+	// done is closed to stop the reaper, which closes stopped once it returned
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+type instanceExpiry struct {
+	ref *types.FnInstanceRef
+	at  time.Time
+	// attempts is the number of times the instance was notified as expired
+	attempts int
 }
 
 var _ state.State = (*adapter)(nil)
 
 // NewState initializes a new state adapter for Memory engine.
-func NewState() state.State {
+// A background goroutine fires the expiry callback once the instances expire.
+func NewState(expiryCallback state.FnExpiryCallback) state.State {
+	a := newAdapter(expiryCallback, reapInterval, state.ExpiryRetry{Min: reapInterval, Max: maxRetryDelay})
+
 	log.Info("State engine 'memory' successfully initialized")
-	return &adapter{
-		store: make(map[string]*types.Function),
+	return a
+}
+
+// newAdapter initializes the adapter and starts the reaper. An expired instance is notified
+// again with the given retry backoff, until it is deleted from the state.
+func newAdapter(expiryCallback state.FnExpiryCallback, reapInterval time.Duration, retry state.ExpiryRetry) *adapter {
+	a := &adapter{
+		store:          make(map[string]*types.Function),
+		instances:      make(map[string]*types.FnInstanceRecord),
+		expiry:         make(map[string]*instanceExpiry),
+		invocations:    make(map[string]time.Time),
+		expiryCallback: expiryCallback,
+		reapInterval:   reapInterval,
+		retry:          retry,
+		done:           make(chan struct{}),
+		stopped:        make(chan struct{}),
 	}
+
+	go a.reap()
+	return a
 }
 
 func (a *adapter) Get(ctx context.Context, key string) (*types.Function, error) {
 	log.Tracef("state/memory: retrieving value for key '%s'", key)
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	v, exists := a.store[key]
 	if !exists {
 		return nil, state.ErrKeyNotFound
@@ -34,8 +85,22 @@ func (a *adapter) Get(ctx context.Context, key string) (*types.Function, error)
 	return v, nil
 }
 
+func (a *adapter) List(ctx context.Context) ([]*types.Function, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	functions := make([]*types.Function, 0, len(a.store))
+	for _, fn := range a.store {
+		functions = append(functions, fn)
+	}
+	return functions, nil
+}
+
 func (a *adapter) Set(ctx context.Context, fn *types.Function) error {
-	log.Tracef("state/memory: setting value '%+v' for key '%s'", fn.Id, fn)
+	log.Tracef("state/memory: setting value '%+v' for key '%s'", fn, fn.Name)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	a.store[fn.Name] = fn
 	return nil
 }
@@ -47,6 +112,132 @@ func (a *adapter) SetMultiple(ctx context.Context, functions []*types.Function)
 	return nil
 }
 
-func (a *adapter) SetWithExpiry(ctx context.Context, key string, expiry time.Duration) error {
-	return errors.New("not supported")
+func (a *adapter) SetWithExpiry(ctx context.Context, ref *types.FnInstanceRef, expiry time.Duration) error {
+	key := ref.String()
+	log.Debugf("Set expiration of %v for key %s", expiry, key)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.expiry[key] = &instanceExpiry{ref: ref, at: time.Now().Add(expiry)}
+	return nil
+}
+
+func (a *adapter) GetExpiry(ctx context.Context, ref *types.FnInstanceRef) (time.Time, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	// An instance already notified as expired isn't warm anymore, even if it is still being torn down
+	e, exists := a.expiry[ref.String()]
+	if !exists || e.attempts > 0 {
+		return time.Time{}, state.ErrKeyNotFound
+	}
+	return e.at, nil
+}
+
+func (a *adapter) SetLastInvocation(ctx context.Context, ref *types.FnInstanceRef, at time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.invocations[ref.String()] = at
+	return nil
+}
+
+func (a *adapter) GetLastInvocation(ctx context.Context, ref *types.FnInstanceRef) (time.Time, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	at, exists := a.invocations[ref.String()]
+	if !exists {
+		return time.Time{}, state.ErrKeyNotFound
+	}
+	return at, nil
+}
+
+func (a *adapter) SetInstance(ctx context.Context, record *types.FnInstanceRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.instances[record.Ref().String()] = record
+	return nil
+}
+
+func (a *adapter) ListInstances(ctx context.Context) ([]*types.FnInstanceRecord, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	records := make([]*types.FnInstanceRecord, 0, len(a.instances))
+	for _, record := range a.instances {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (a *adapter) DeleteInstance(ctx context.Context, ref *types.FnInstanceRef) error {
+	key := ref.String()
+	log.Tracef("state/memory: deleting instance '%s'", key)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.instances, key)
+	delete(a.expiry, key)
+	delete(a.invocations, key)
+	return nil
+}
+
+func (a *adapter) Ping(ctx context.Context) error {
+	// The memory engine is always reachable
+	return nil
+}
+
+func (a *adapter) Delete(ctx context.Context, key string) error {
+	log.Tracef("state/memory: deleting key '%s'", key)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.store, key)
+	return nil
+}
+
+func (a *adapter) Close() error {
+	a.closeOnce.Do(func() {
+		close(a.done)
+		<-a.stopped
+	})
+	return nil
+}
+
+// reap periodically notifies the expiry callback of the expired instances. They are kept until they are
+// deleted from the state, and notified again with a backoff in case the callback failed to tear them down.
+func (a *adapter) reap() {
+	defer close(a.stopped)
+
+	ticker := time.NewTicker(a.reapInterval)
+	defer ticker.Stop()
+
+	for {
+		var now time.Time
+		select {
+		case <-a.done:
+			return
+		case now = <-ticker.C:
+		}
+
+		var expired []*types.FnInstanceRef
+
+		a.mu.Lock()
+		for _, e := range a.expiry {
+			if !e.at.After(now) {
+				e.attempts++
+				e.at = now.Add(a.retry.Delay(e.attempts))
+				expired = append(expired, e.ref)
+			}
+		}
+		a.mu.Unlock()
+
+		// The callback is called without holding the lock, as it may use the state
+		for _, ref := range expired {
+			log.Tracef("Key %s has expired", ref)
+			a.expiryCallback(ref)
+		}
+	}
 }
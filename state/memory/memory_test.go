@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/morty-faas/controller/internal/testutil"
+	"github.com/morty-faas/controller/state"
+	"github.com/morty-faas/controller/types"
+)
+
+var testRetry = state.ExpiryRetry{Min: 20 * time.Millisecond, Max: 40 * time.Millisecond}
+
+// newTestAdapter initializes an adapter reaping the expired instances every few milliseconds
+func newTestAdapter(t *testing.T) (*adapter, testutil.Expirations) {
+	t.Helper()
+
+	expired := testutil.NewExpirations()
+	a := newAdapter(expired.Callback, 5*time.Millisecond, testRetry)
+	t.Cleanup(func() { a.Close() })
+	return a, expired
+}
+
+func TestReapNotifiesExpiredInstance(t *testing.T) {
+	ctx := context.Background()
+	a, expired := newTestAdapter(t)
+
+	ref := &types.FnInstanceRef{FunctionId: "fn-1", InstanceId: "instance-1"}
+	if err := a.SetWithExpiry(ctx, ref, 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetExpiry(ctx, ref); err != nil {
+		t.Fatalf("GetExpiry() = %v, want the instance to be warm", err)
+	}
+
+	expired.Wait(t, ref, time.Second)
+
+	if _, err := a.GetExpiry(ctx, ref); !errors.Is(err, state.ErrKeyNotFound) {
+		t.Fatalf("GetExpiry() = %v, want %v once expired", err, state.ErrKeyNotFound)
+	}
+}
+
+func TestReapRetriesUntilInstanceDeleted(t *testing.T) {
+	ctx := context.Background()
+	a, expired := newTestAdapter(t)
+
+	ref := &types.FnInstanceRef{FunctionId: "fn-1", InstanceId: "instance-1"}
+	if err := a.SetWithExpiry(ctx, ref, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// The callback didn't delete the instance, so it is notified again after the backoff
+	expired.Wait(t, ref, time.Second)
+	start := time.Now()
+	expired.Wait(t, ref, time.Second)
+	if elapsed := time.Since(start); elapsed < testRetry.Min/2 {
+		t.Fatalf("instance notified again after %v, want the retry backoff to be applied", elapsed)
+	}
+
+	if err := a.DeleteInstance(ctx, ref); err != nil {
+		t.Fatal(err)
+	}
+	expired.None(t, 2*testRetry.Max)
+}
+
+func TestSetWithExpiryExtendsKeepWarm(t *testing.T) {
+	ctx := context.Background()
+	a, expired := newTestAdapter(t)
+
+	ref := &types.FnInstanceRef{FunctionId: "fn-1", InstanceId: "instance-1"}
+	if err := a.SetWithExpiry(ctx, ref, 0); err != nil {
+		t.Fatal(err)
+	}
+	expired.Wait(t, ref, time.Second)
+
+	// The instance is warm again once it is invoked, even though it was being torn down
+	if err := a.SetWithExpiry(ctx, ref, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetExpiry(ctx, ref); err != nil {
+		t.Fatalf("GetExpiry() = %v, want the instance to be warm", err)
+	}
+	expired.None(t, 2*testRetry.Max)
+}
+
+func TestCloseTwice(t *testing.T) {
+	a := newAdapter(func(ref *types.FnInstanceRef) {}, time.Millisecond, testRetry)
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrationsLockId is the identifier of the advisory lock preventing
+// multiple controllers to run the migrations concurrently
+const migrationsLockId = 7_346_211
+
+// migrate applies the migrations that haven't been applied yet to the database. Migrations
+// are named <version>_<description>.sql and are applied in order, each within its own transaction.
+func migrate(ctx context.Context, db *sql.DB) error {
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		version, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(file, "migrations/"), "_", 2)[0])
+		if err != nil {
+			return err
+		}
+
+		if err := applyMigration(ctx, db, file, version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyMigration is a helper function to apply a single migration if it hasn't been applied yet
+func applyMigration(ctx context.Context, db *sql.DB, file string, version int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The lock is released at the end of the transaction
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationsLockId); err != nil {
+		return err
+	}
+
+	var applied bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version).Scan(&applied); err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+
+	query, err := migrations.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Applying Postgres migration %s", file)
+	if _, err := tx.ExecContext(ctx, string(query)); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
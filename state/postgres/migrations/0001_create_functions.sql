@@ -0,0 +1,19 @@
+CREATE TABLE functions (
+    name          TEXT PRIMARY KEY,
+    id            TEXT NOT NULL,
+    image_url     TEXT NOT NULL,
+    revision      INTEGER NOT NULL DEFAULT 0,
+    aliases       JSONB NOT NULL DEFAULT '{}',
+    min_instances INTEGER NOT NULL DEFAULT 0,
+    max_instances INTEGER NOT NULL DEFAULT 0,
+    idle_timeout  INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE revisions (
+    function_name TEXT NOT NULL REFERENCES functions (name) ON DELETE CASCADE,
+    number        INTEGER NOT NULL,
+    id            TEXT NOT NULL,
+    image_url     TEXT NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (function_name, number)
+);
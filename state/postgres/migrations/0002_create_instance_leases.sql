@@ -0,0 +1,8 @@
+CREATE TABLE instance_leases (
+    key         TEXT PRIMARY KEY,
+    function_id TEXT NOT NULL,
+    instance_id TEXT NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX instance_leases_expires_at_idx ON instance_leases (expires_at);
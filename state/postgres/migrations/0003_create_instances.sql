@@ -0,0 +1,8 @@
+CREATE TABLE instances (
+    key           TEXT PRIMARY KEY,
+    function_id   TEXT NOT NULL,
+    instance_id   TEXT NOT NULL,
+    function_name TEXT NOT NULL,
+    revision      INTEGER NOT NULL,
+    endpoint      TEXT NOT NULL
+);
@@ -0,0 +1,2 @@
+-- attempts counts the expiry notifications of a lease, which are retried until the instance is deleted
+ALTER TABLE instance_leases ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+CREATE TABLE instance_invocations (
+    key        TEXT PRIMARY KEY,
+    invoked_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,146 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrMultipleModes = errors.New("sentinel and cluster modes can't be configured at the same time")
+	ErrClusterDB     = errors.New("cluster mode only supports the database 0")
+	ErrInvalidCA     = errors.New("no valid certificate found in the CA file")
+)
+
+// TLSConfig hold the TLS configuration of the connections to Redis
+type TLSConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// CAFile is the path of a PEM encoded CA bundle used to verify the server certificate,
+	// the system pool is used if empty
+	CAFile string `yaml:"caFile"`
+	// CertFile and KeyFile are the paths of a PEM encoded client certificate and its key
+	CertFile           string `yaml:"certFile"`
+	KeyFile            string `yaml:"keyFile"`
+	ServerName         string `yaml:"serverName"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+}
+
+// SentinelConfig hold the configuration of the Sentinel failover mode
+type SentinelConfig struct {
+	MasterName string   `yaml:"masterName"`
+	Addrs      []string `yaml:"addrs"`
+	// Username and Password are used to authenticate against the Sentinel nodes,
+	// the Redis nodes use the credentials of the root configuration
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// ClusterConfig hold the configuration of the Redis Cluster mode
+type ClusterConfig struct {
+	Addrs []string `yaml:"addrs"`
+}
+
+// mode returns the name of the mode selected by the configuration
+func (c *Config) mode() string {
+	switch {
+	case c.Sentinel.MasterName != "":
+		return "sentinel"
+	case len(c.Cluster.Addrs) > 0:
+		return "cluster"
+	default:
+		return "standalone"
+	}
+}
+
+// newClient initializes a Redis client for the mode selected by the configuration.
+func newClient(cfg *Config) (redis.UniversalClient, error) {
+	if cfg.Sentinel.MasterName != "" && len(cfg.Cluster.Addrs) > 0 {
+		return nil, ErrMultipleModes
+	}
+
+	tlsConfig, err := cfg.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.mode() {
+	case "sentinel":
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.Sentinel.MasterName,
+			SentinelAddrs:    cfg.Sentinel.Addrs,
+			SentinelUsername: cfg.Sentinel.Username,
+			SentinelPassword: cfg.Sentinel.Password,
+			Username:         cfg.Username,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+			TLSConfig:        tlsConfig,
+		}), nil
+	case "cluster":
+		if cfg.DB != 0 {
+			return nil, ErrClusterDB
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:     cfg.Cluster.Addrs,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+			TLSConfig: tlsConfig,
+		}), nil
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:      cfg.Addr,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+			DB:        cfg.DB,
+			TLSConfig: tlsConfig,
+		}), nil
+	}
+}
+
+// build returns the TLS configuration of the client, or nil if TLS isn't enabled
+func (c *TLSConfig) build() (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, ErrInvalidCA
+		}
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// forEachNode calls fn with the client, or with each master node in Cluster mode,
+// as keyspace notifications and scans are local to a node.
+func forEachNode(ctx context.Context, client redis.UniversalClient, fn func(ctx context.Context, node *redis.Client) error) error {
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, fn)
+	}
+	return fn(ctx, client.(*redis.Client))
+}
@@ -0,0 +1,216 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/morty-faas/controller/types"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// minResubscribeBackoff and maxResubscribeBackoff bound the delay between two subscription attempts
+	minResubscribeBackoff = 100 * time.Millisecond
+	maxResubscribeBackoff = 30 * time.Second
+	// sweepInterval is the interval at which the instances whose key expired without notification are looked for
+	sweepInterval = time.Minute
+)
+
+// expirySubscriber receives the expiration events published by a Redis node.
+// If the subscription is lost, it resubscribes with an exponential backoff.
+type expirySubscriber struct {
+	adapter *adapter
+	node    *redis.Client
+	channel string
+	pubsub  *redis.PubSub
+	// configRefused is set once the node refused to enable the keyspace events, so it's only reported once
+	configRefused bool
+}
+
+// subscribe enables the keyspace events on the node, as they may have been reset by a
+// restart, and subscribes to the expiration events. Managed services often refuse the CONFIG
+// command: the events must then be enabled on the node, and the sweep catches the missed expirations.
+func (s *expirySubscriber) subscribe(ctx context.Context) error {
+	err := s.node.ConfigSet(ctx, "notify-keyspace-events", "KEA").Err()
+	var refused redis.Error
+	switch {
+	case err == nil:
+	case errors.As(err, &refused):
+		if !s.configRefused {
+			s.configRefused = true
+			log.Warnf("Failed to enable the keyspace events on %s, make sure `notify-keyspace-events` includes `Ex`: %v", s.node.Options().Addr, err)
+		}
+	default:
+		return fmt.Errorf("failed to enable keyspace events: %w", err)
+	}
+
+	pubsub := s.node.Subscribe(ctx, s.channel)
+
+	// Wait for the confirmation, so no event is missed once we return
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return err
+	}
+
+	s.pubsub = pubsub
+	return nil
+}
+
+// run handles the expiration events until the context is done.
+func (s *expirySubscriber) run(ctx context.Context) {
+	defer s.adapter.wg.Done()
+
+	for {
+		err := s.receive(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Warnf("Lost the subscription to the Redis expiry events: %v", err)
+		if !s.resubscribe(ctx) {
+			return
+		}
+
+		// Keys may have expired while we weren't subscribed
+		s.adapter.triggerSweep()
+	}
+}
+
+// receive handles the expiration events until the subscription fails or the context is done.
+// The subscription is closed once it returns.
+func (s *expirySubscriber) receive(ctx context.Context) error {
+	pubsub := s.pubsub
+
+	// A blocking read isn't interrupted by the context, but closing the subscription does
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		}
+		pubsub.Close()
+	}()
+
+	for {
+		message, err := pubsub.ReceiveMessage(ctx)
+		if err != nil {
+			return err
+		}
+		s.adapter.handleExpiredKey(message.Payload)
+	}
+}
+
+// resubscribe tries to subscribe again with an exponential backoff. It returns false if the
+// context is done before the subscription succeeds.
+func (s *expirySubscriber) resubscribe(ctx context.Context) bool {
+	backoff := minResubscribeBackoff
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+
+		err := s.subscribe(ctx)
+		if err == nil {
+			log.Info("Resubscribed to the Redis expiry events")
+			return true
+		}
+
+		if backoff *= 2; backoff > maxResubscribeBackoff {
+			backoff = maxResubscribeBackoff
+		}
+		log.Errorf("Failed to resubscribe to the Redis expiry events, retrying in %v: %v", backoff, err)
+	}
+}
+
+// handleExpiredKey calls the expiry callback if the key is one of our instance keys
+func (a *adapter) handleExpiredKey(key string) {
+	log.Tracef("Key %s has expired", key)
+
+	// The database may be shared with other applications, so we only
+	// handle the expiration of our own instance keys
+	if !strings.HasPrefix(key, a.keys.instances) {
+		return
+	}
+
+	ref, err := types.ParseFnInstanceRef(strings.TrimPrefix(key, a.keys.instances))
+	if err != nil {
+		log.Debugf("Ignoring expiry event: %v", err)
+		return
+	}
+	a.expiryCallback(ref)
+}
+
+// sweep looks for the instances whose key expired without the expiry callback being called,
+// e.g. while the subscription was lost. It runs on startup, on demand and periodically, until
+// the context is done.
+func (a *adapter) sweep(ctx context.Context) {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.sweepExpired(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("failed to sweep expired instances: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-a.sweepNow:
+		}
+	}
+}
+
+// triggerSweep requests a sweep, without waiting for it
+func (a *adapter) triggerSweep() {
+	select {
+	case a.sweepNow <- struct{}{}:
+	default:
+		// A sweep is already pending
+	}
+}
+
+// sweepExpired is a helper function to call the expiry callback for the instances of the expiring
+// set whose key doesn't exist anymore. They are removed from the set by DeleteInstance.
+func (a *adapter) sweepExpired(ctx context.Context) error {
+	members, err := a.client.SMembers(ctx, a.keys.expiring).Result()
+	if err != nil || len(members) == 0 {
+		return err
+	}
+
+	exists := make([]*redis.IntCmd, len(members))
+	_, err = a.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, member := range members {
+			exists[i] = pipe.Exists(ctx, a.keys.instances+member)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, member := range members {
+		if exists[i].Val() > 0 {
+			continue
+		}
+
+		ref, err := types.ParseFnInstanceRef(member)
+		if err != nil {
+			log.Debugf("Ignoring expiring instance: %v", err)
+			continue
+		}
+
+		log.Debugf("Instance %s expired without notification", ref)
+		a.expiryCallback(ref)
+	}
+	return nil
+}
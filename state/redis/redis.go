@@ -2,62 +2,186 @@ package redis
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/morty-faas/controller/state"
 	"github.com/morty-faas/controller/types"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
 )
 
+// defaultPrefix is the prefix of the keys when no prefix is configured
+const defaultPrefix = "morty"
+
 // adapter is an implementation of the state.State interface
 type adapter struct {
-	client *redis.Client
+	client         redis.UniversalClient
+	keys           keyspace
+	expiryCallback state.FnExpiryCallback
+
+	// cancel stops the expiry subscribers and the sweeper, which are tracked by wg
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	sweepNow chan struct{}
 }
 
 // Config hold the configuration about the Redis state adapter
 type Config struct {
-	Addr string `yaml:"addr"`
+	// Addr is the address of the Redis server in standalone mode
+	Addr     string `yaml:"addr"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// DB is the index of the Redis database to use
+	DB int `yaml:"db"`
+	// Prefix is prepended to all the keys written by the adapter, so the
+	// database can be shared with other applications. Defaults to "morty".
+	Prefix string `yaml:"prefix"`
+
+	TLS TLSConfig `yaml:"tls"`
+	// Sentinel and Cluster are mutually exclusive, the standalone mode is used if none is configured
+	Sentinel SentinelConfig `yaml:"sentinel"`
+	Cluster  ClusterConfig  `yaml:"cluster"`
+}
+
+// keyspace builds the keys of the adapter. Functions and instances live in separate
+// namespaces, so a function name can't collide with an instance key. The instance keys
+// share the {<prefix>} hash tag, so in Cluster mode they're stored in the same slot and
+// can be updated within a single transaction.
+//
+//	<prefix>:functions:<name>                 hash holding a function definition
+//	{<prefix>}:instances:<fnId>/<instanceId>  key holding an instance expiry
+//	{<prefix>}:expiring                       set holding the references of the instances having an expiry
+//	{<prefix>}:records                        hash holding the instance records
+//	{<prefix>}:invocations                    hash holding the unix time in milliseconds of the last invocation of the instances
+//	<prefix>:locks:<name>                     key holding the owner of a lock
+type keyspace struct {
+	functions   string
+	instances   string
+	expiring    string
+	records     string
+	invocations string
+	locks       string
+}
+
+func newKeyspace(prefix string) keyspace {
+	tag := "{" + prefix + "}"
+	return keyspace{
+		functions:   prefix + ":functions:",
+		instances:   tag + ":instances:",
+		expiring:    tag + ":expiring",
+		records:     tag + ":records",
+		invocations: tag + ":invocations",
+		locks:       prefix + ":locks:",
+	}
+}
+
+func (k keyspace) function(name string) string {
+	return k.functions + name
 }
 
-var _ state.State = (*adapter)(nil)
+func (k keyspace) instance(ref *types.FnInstanceRef) string {
+	return k.instances + ref.String()
+}
+
+var (
+	// acquireLockScript sets the lock owner if the lock is free or already owned by the holder
+	acquireLockScript = redis.NewScript(`
+		local owner = redis.call("GET", KEYS[1])
+		if owner == false or owner == ARGV[1] then
+			redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+			return 1
+		end
+		return 0
+	`)
+	// releaseLockScript deletes the lock if it is owned by the holder
+	releaseLockScript = redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("DEL", KEYS[1])
+		end
+		return 0
+	`)
+)
+
+var (
+	_ state.State  = (*adapter)(nil)
+	_ state.Locker = (*adapter)(nil)
+)
 
 // NewState initializes a new state adapter for Redis based on the given configuration.
 // An error could be returned if any errors happens during the adapter initialization.
 func NewState(cfg *Config, expiryCallback state.FnExpiryCallback) (state.State, error) {
-	log.Debugf("Bootstrapping Redis state adapter with options: %#v", cfg)
-	client := redis.NewClient(&redis.Options{
-		Addr: cfg.Addr,
-		DB:   0,
-	})
+	log.Debugf("Bootstrapping Redis state adapter in %s mode", cfg.mode())
+	client, err := newClient(cfg)
+	if err != nil {
+		log.Errorf("Failed to configure Redis client: %v", err)
+		return nil, err
+	}
+
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
 
-	// Enable Keyspace events as we will need them to handle function instances expiration
-	if _, err := client.ConfigSet(context.Background(), "notify-keyspace-events", "KEA").Result(); err != nil {
-		log.Errorf("Failed to enable Redis Keyspace Events: %v", err)
+	// Trace every command sent to Redis
+	if err := redisotel.InstrumentTracing(client); err != nil {
 		return nil, err
 	}
 
-	// this is telling redis to subscribe to events published in the keyevent channel, specifically for expired events
-	pubsub := client.PSubscribe(context.Background(), "__keyevent@0__:expired")
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &adapter{
+		client:         client,
+		keys:           newKeyspace(prefix),
+		expiryCallback: expiryCallback,
+		cancel:         cancel,
+		sweepNow:       make(chan struct{}, 1),
+	}
+
+	// Keyspace events are local to a node, so in Cluster mode we have to
+	// enable and subscribe to them on each master node
+	channel := fmt.Sprintf("__keyevent@%d__:expired", cfg.DB)
 
-	go func(redis.PubSub) {
-		for {
-			message, err := pubsub.ReceiveMessage(context.Background())
-			if err != nil {
-				log.Errorf("failed to receive expiry event: %v", err)
-				continue
-			}
-			log.Tracef("Key %s has expired", message.Payload)
-			expiryCallback(message.Payload)
+	var mu sync.Mutex
+	var subscribers []*expirySubscriber
+	err = forEachNode(ctx, client, func(ctx context.Context, node *redis.Client) error {
+		s := &expirySubscriber{adapter: a, node: node, channel: channel}
+		if err := s.subscribe(ctx); err != nil {
+			return err
 		}
-	}(*pubsub)
+
+		mu.Lock()
+		subscribers = append(subscribers, s)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Failed to subscribe to Redis expiry events: %v", err)
+		for _, s := range subscribers {
+			s.pubsub.Close()
+		}
+		cancel()
+		return nil, err
+	}
+
+	a.wg.Add(len(subscribers) + 1)
+	for _, s := range subscribers {
+		go s.run(ctx)
+	}
+	// The keys that expired while no controller was running are handled right away
+	go a.sweep(ctx)
 
 	log.Info("State engine 'redis' successfully initialized")
-	return &adapter{client}, nil
+	return a, nil
 }
 
 func (a *adapter) Get(ctx context.Context, key string) (*types.Function, error) {
-	r := a.client.HGetAll(ctx, key)
+	r := a.client.HGetAll(ctx, a.keys.function(key))
 	log.Tracef("state/redis: %s", r.String())
 
 	res, err := r.Result()
@@ -75,11 +199,75 @@ func (a *adapter) Get(ctx context.Context, key string) (*types.Function, error)
 		ImageURL: res["imageUrl"],
 	}
 
+	// Functions registered before revisions were introduced don't have these fields
+	if v, ok := res["revision"]; ok {
+		if fn.Revision, err = strconv.Atoi(v); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := res["revisions"]; ok {
+		if err := fn.Revisions.UnmarshalBinary([]byte(v)); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := res["aliases"]; ok {
+		if err := fn.Aliases.UnmarshalBinary([]byte(v)); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := res["minInstances"]; ok {
+		if fn.MinInstances, err = strconv.Atoi(v); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := res["maxInstances"]; ok {
+		if fn.MaxInstances, err = strconv.Atoi(v); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := res["idleTimeout"]; ok {
+		if fn.IdleTimeout, err = strconv.Atoi(v); err != nil {
+			return nil, err
+		}
+	}
+
 	return fn, nil
 }
 
+func (a *adapter) List(ctx context.Context) ([]*types.Function, error) {
+	// In Cluster mode, the keys are spread across the master nodes which are scanned concurrently
+	var mu sync.Mutex
+	var keys []string
+	err := forEachNode(ctx, a.client, func(ctx context.Context, node *redis.Client) error {
+		iter := node.Scan(ctx, 0, a.keys.functions+"*", 100).Iterator()
+		for iter.Next(ctx) {
+			mu.Lock()
+			keys = append(keys, iter.Val())
+			mu.Unlock()
+		}
+		return iter.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	functions := []*types.Function{}
+	for _, key := range keys {
+		fn, err := a.Get(ctx, strings.TrimPrefix(key, a.keys.functions))
+		if err != nil {
+			return nil, err
+		}
+		// The function may have been deleted since the scan
+		if fn != nil {
+			functions = append(functions, fn)
+		}
+	}
+
+	return functions, nil
+}
+
 func (a *adapter) Set(ctx context.Context, fn *types.Function) error {
-	r := a.client.HSet(ctx, fn.Name, fn)
+	r := a.client.HSet(ctx, a.keys.function(fn.Name), fn)
 	log.Tracef("state/redis: %s", r.String())
 	_, err := r.Result()
 	return err
@@ -95,9 +283,123 @@ func (a *adapter) SetMultiple(ctx context.Context, functions []*types.Function)
 	return errors
 }
 
-func (a *adapter) SetWithExpiry(ctx context.Context, key string, expiry time.Duration) error {
+func (a *adapter) SetWithExpiry(ctx context.Context, ref *types.FnInstanceRef, expiry time.Duration) error {
+	key := a.keys.instance(ref)
 	log.Debugf("Set expiration of %v for key %s", expiry, key)
 
-	_, err := a.client.Set(ctx, key, "", expiry).Result()
+	// The instance is also added to the expiring set, so its expiry can be handled by a sweep if
+	// the expiration event is missed. Both keys share the same slot, so the transaction is atomic
+	// in Cluster mode too.
+	_, err := a.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, "", expiry)
+		pipe.SAdd(ctx, a.keys.expiring, ref.String())
+		return nil
+	})
+	return err
+}
+
+func (a *adapter) GetExpiry(ctx context.Context, ref *types.FnInstanceRef) (time.Time, error) {
+	r := a.client.PTTL(ctx, a.keys.instance(ref))
+	log.Tracef("state/redis: %s", r.String())
+
+	ttl, err := r.Result()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// A negative TTL means that the key doesn't exist (-2) or doesn't have an expiry (-1)
+	if ttl < 0 {
+		return time.Time{}, state.ErrKeyNotFound
+	}
+
+	return time.Now().Add(ttl), nil
+}
+
+func (a *adapter) SetLastInvocation(ctx context.Context, ref *types.FnInstanceRef, at time.Time) error {
+	r := a.client.HSet(ctx, a.keys.invocations, ref.String(), at.UnixMilli())
+	log.Tracef("state/redis: %s", r.String())
+	return r.Err()
+}
+
+func (a *adapter) GetLastInvocation(ctx context.Context, ref *types.FnInstanceRef) (time.Time, error) {
+	r := a.client.HGet(ctx, a.keys.invocations, ref.String())
+	log.Tracef("state/redis: %s", r.String())
+
+	ms, err := r.Int64()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, state.ErrKeyNotFound
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(ms), nil
+}
+
+func (a *adapter) SetInstance(ctx context.Context, record *types.FnInstanceRecord) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	r := a.client.HSet(ctx, a.keys.records, record.Ref().String(), value)
+	log.Tracef("state/redis: %s", r.String())
+	return r.Err()
+}
+
+func (a *adapter) ListInstances(ctx context.Context) ([]*types.FnInstanceRecord, error) {
+	r := a.client.HVals(ctx, a.keys.records)
+	log.Tracef("state/redis: %s", r.String())
+
+	values, err := r.Result()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*types.FnInstanceRecord, 0, len(values))
+	for _, v := range values {
+		record := &types.FnInstanceRecord{}
+		if err := json.Unmarshal([]byte(v), record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (a *adapter) DeleteInstance(ctx context.Context, ref *types.FnInstanceRef) error {
+	// The instance keys share the same slot, so the transaction is atomic in Cluster mode too
+	_, err := a.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, a.keys.records, ref.String())
+		pipe.Del(ctx, a.keys.instance(ref))
+		pipe.SRem(ctx, a.keys.expiring, ref.String())
+		pipe.HDel(ctx, a.keys.invocations, ref.String())
+		return nil
+	})
 	return err
 }
+
+func (a *adapter) Ping(ctx context.Context) error {
+	return a.client.Ping(ctx).Err()
+}
+
+func (a *adapter) Delete(ctx context.Context, key string) error {
+	r := a.client.Del(ctx, a.keys.function(key))
+	log.Tracef("state/redis: %s", r.String())
+	_, err := r.Result()
+	return err
+}
+
+func (a *adapter) Close() error {
+	a.cancel()
+	a.wg.Wait()
+	return a.client.Close()
+}
+
+func (a *adapter) AcquireLock(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	acquired, err := acquireLockScript.Run(ctx, a.client, []string{a.keys.locks + name}, holder, ttl.Milliseconds()).Int()
+	return acquired == 1, err
+}
+
+func (a *adapter) ReleaseLock(ctx context.Context, name, holder string) error {
+	return releaseLockScript.Run(ctx, a.client, []string{a.keys.locks + name}, holder).Err()
+}
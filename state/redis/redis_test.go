@@ -0,0 +1,158 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/morty-faas/controller/internal/testutil"
+	"github.com/morty-faas/controller/types"
+)
+
+// newTestAdapter initializes an adapter connected to an in-memory Redis server. The server
+// neither supports the CONFIG command nor publishes keyspace events: the tests publish them.
+func newTestAdapter(t *testing.T) (*adapter, *miniredis.Miniredis, testutil.Expirations) {
+	t.Helper()
+
+	m := miniredis.RunT(t)
+	expired := testutil.NewExpirations()
+	s, err := NewState(&Config{Addr: m.Addr()}, expired.Callback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s.(*adapter), m, expired
+}
+
+// publishExpired publishes the expiration event of the instance key, until a subscriber receives it
+func publishExpired(t *testing.T, a *adapter, m *miniredis.Miniredis, ref *types.FnInstanceRef) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Publish("__keyevent@0__:expired", a.keys.instance(ref)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no subscriber received the expiration event")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// hashTag returns the part of the key used to compute its Cluster slot
+func hashTag(key string) string {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			return key[start+1 : start+1+end]
+		}
+	}
+	return key
+}
+
+func TestKeyspaceInstanceKeysShareSlot(t *testing.T) {
+	keys := newKeyspace("morty")
+	ref := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-1"}
+
+	for _, key := range []string{keys.instance(ref), keys.expiring, keys.records, keys.invocations} {
+		if tag := hashTag(key); tag != "morty" {
+			t.Errorf("key %s is hashed on %q, want %q", key, tag, "morty")
+		}
+	}
+}
+
+func TestExpirationEvent(t *testing.T) {
+	a, m, expired := newTestAdapter(t)
+	ref := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-1"}
+
+	// The events of the keys which aren't ours are ignored
+	m.Publish("__keyevent@0__:expired", "other:instances:"+ref.String())
+	expired.None(t, 50*time.Millisecond)
+
+	publishExpired(t, a, m, ref)
+	expired.Wait(t, ref, time.Second)
+}
+
+func TestSweepExpiredWithoutNotification(t *testing.T) {
+	ctx := context.Background()
+	a, m, expired := newTestAdapter(t)
+	ref := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-1"}
+	warm := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-2"}
+
+	if err := a.SetWithExpiry(ctx, ref, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetWithExpiry(ctx, warm, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	m.FastForward(2 * time.Second)
+
+	// The instance is notified on each sweep, until it is deleted
+	for i := 0; i < 2; i++ {
+		a.triggerSweep()
+		expired.Wait(t, ref, time.Second)
+	}
+
+	if err := a.DeleteInstance(ctx, ref); err != nil {
+		t.Fatal(err)
+	}
+	// The startup sweep may have notified the instance too
+	time.Sleep(50 * time.Millisecond)
+	for len(expired) > 0 {
+		<-expired
+	}
+	members, err := m.Members(a.keys.expiring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 || members[0] != warm.String() {
+		t.Fatalf("expiring instances = %v, want only %s", members, warm)
+	}
+	a.triggerSweep()
+	expired.None(t, 50*time.Millisecond)
+}
+
+func TestResubscribeAfterConnectionLoss(t *testing.T) {
+	ctx := context.Background()
+	a, m, expired := newTestAdapter(t)
+	missed := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-1"}
+
+	if err := a.SetWithExpiry(ctx, missed, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// The instance expires while the subscription is lost
+	m.Close()
+	m.FastForward(2 * time.Second)
+	time.Sleep(3 * minResubscribeBackoff)
+	if err := m.Restart(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The sweep triggered once resubscribed catches the missed expiration
+	expired.Wait(t, missed, 5*time.Second)
+
+	ref := &types.FnInstanceRef{FunctionId: "weatho-r1", InstanceId: "instance-2"}
+	publishExpired(t, a, m, ref)
+	expired.Wait(t, ref, time.Second)
+}
+
+func TestCloseWhileResubscribing(t *testing.T) {
+	a, m, _ := newTestAdapter(t)
+
+	// The subscriber waits for the server to come back
+	m.Close()
+	time.Sleep(3 * minResubscribeBackoff)
+
+	closed := make(chan struct{})
+	go func() {
+		a.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the adapter to close while resubscribing")
+	}
+}
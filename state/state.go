@@ -12,17 +12,77 @@ var (
 	ErrKeyNotFound = errors.New("key not found")
 )
 
-type FnExpiryCallback func(string)
+// FnExpiryCallback is called with the reference of a function instance once its keep-warm period expired
+type FnExpiryCallback func(*types.FnInstanceRef)
+
+// ExpiryRetry is the backoff with which an expired instance is notified again, until it is deleted
+// from the state. It prevents an instance from leaking if the callback failed to tear it down.
+type ExpiryRetry struct {
+	// Min is the delay before the first retry, doubled on each subsequent retry
+	Min time.Duration
+	// Max is the maximum delay between two retries
+	Max time.Duration
+}
+
+// Delay returns the delay before notifying again an instance already notified the given number of times.
+func (r ExpiryRetry) Delay(attempts int) time.Duration {
+	delay := r.Min
+	for i := 1; i < attempts && delay < r.Max; i++ {
+		delay *= 2
+	}
+	if delay > r.Max {
+		return r.Max
+	}
+	return delay
+}
 
 // State is a generic interface for our controller state
 type State interface {
 	// Get retrieve the value associated to the given key.
 	// If the key doesn't exists, an error ErrKeyNotFound will be returned
 	Get(ctx context.Context, key string) (*types.Function, error)
+	// List retrieve all the functions of the state
+	List(ctx context.Context) ([]*types.Function, error)
 	// Set a tuple of key/value in the state
 	Set(ctx context.Context, fn *types.Function) error
 	// SetMultiple set multiple keys in one call
 	SetMultiple(ctx context.Context, functions []*types.Function) []error
 
-	SetWithExpiry(ctx context.Context, key string, expiry time.Duration) error
+	// SetWithExpiry keeps the instance warm for the given duration.
+	// Once expired, the FnExpiryCallback is called with the instance reference.
+	SetWithExpiry(ctx context.Context, ref *types.FnInstanceRef, expiry time.Duration) error
+	// GetExpiry retrieve the date at which the given instance will expire.
+	// If the instance doesn't have an expiry, an error ErrKeyNotFound will be returned
+	GetExpiry(ctx context.Context, ref *types.FnInstanceRef) (time.Time, error)
+	// SetLastInvocation records the date of the last invocation handled by the instance, so it is
+	// known by all the controllers sharing the state.
+	SetLastInvocation(ctx context.Context, ref *types.FnInstanceRef, at time.Time) error
+	// GetLastInvocation retrieve the date of the last invocation handled by the instance.
+	// If no invocation has been recorded, an error ErrKeyNotFound will be returned
+	GetLastInvocation(ctx context.Context, ref *types.FnInstanceRef) (time.Time, error)
+	// SetInstance register the instance into the state, or update it if it's already registered
+	SetInstance(ctx context.Context, record *types.FnInstanceRecord) error
+	// ListInstances retrieve all the instances registered into the state, across all functions
+	ListInstances(ctx context.Context) ([]*types.FnInstanceRecord, error)
+	// DeleteInstance remove the instance, its expiry and its last invocation from the state.
+	// Deleting an instance that doesn't exists is not considered as an error.
+	DeleteInstance(ctx context.Context, ref *types.FnInstanceRef) error
+	// Ping checks that the underlying storage is reachable
+	Ping(ctx context.Context) error
+	// Delete remove the value associated to the given key from the state.
+	// Deleting a key that doesn't exists is not considered as an error.
+	Delete(ctx context.Context, key string) error
+	// Close stops the background tasks of the state, and releases the underlying storage.
+	// The expiry callback isn't called anymore once it returns.
+	Close() error
+}
+
+// Locker is implemented by the state engines which can be shared between multiple controllers.
+// It is used to elect the controller handling the background tasks.
+type Locker interface {
+	// AcquireLock acquires the lock for the given holder, or renews it if the holder already
+	// owns it, until the ttl elapses. It returns false if the lock is owned by another holder.
+	AcquireLock(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// ReleaseLock releases the lock if it is owned by the given holder
+	ReleaseLock(ctx context.Context, name, holder string) error
 }
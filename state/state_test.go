@@ -0,0 +1,27 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpiryRetryDelay(t *testing.T) {
+	retry := ExpiryRetry{Min: time.Second, Max: 5 * time.Second}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second},
+		{100, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := retry.Delay(tt.attempts); got != tt.want {
+			t.Errorf("Delay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// ServiceName is the name under which the controller spans are reported
+const ServiceName = "morty-controller"
+
+// Config hold the configuration about the OpenTelemetry tracing
+type Config struct {
+	// Endpoint is the address (host:port) of the OTLP HTTP collector.
+	// Tracing is disabled if no endpoint is configured.
+	Endpoint string `yaml:"endpoint"`
+	// Insecure disables TLS when exporting the spans to the collector
+	Insecure bool `yaml:"insecure"`
+	// SampleRatio is the ratio of the traces that are sampled, between 0 and 1
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+// ShutdownFunc flushes the pending spans and stops the exporter
+type ShutdownFunc func(ctx context.Context) error
+
+// Init configures the global OpenTelemetry tracer provider and propagator based on the given
+// configuration. The returned function must be called before exiting the program to flush
+// the pending spans.
+func Init(cfg *Config) (ShutdownFunc, error) {
+	if cfg.Endpoint == "" {
+		log.Debug("No tracing endpoint configured, tracing is disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(tp)
+	// W3C trace context is propagated to the function instances
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	log.Infof("Tracing enabled, exporting spans to %s", cfg.Endpoint)
+	return tp.Shutdown, nil
+}
@@ -1,12 +1,215 @@
 package types
 
-import "net/url"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strings"
+	"time"
+)
 
 type Function struct {
 	Id string `json:"id" redis:"id"`
 	// We don't want to serialize the name as a Redis HSET as we use the ID as the key
 	Name     string `json:"name" redis:"-"`
 	ImageURL string `json:"image" redis:"imageUrl"`
+	// Revision is the number of the revision currently described by Id and ImageURL
+	Revision int `json:"revision" redis:"revision"`
+	// Revisions is the immutable history of the function, ordered by revision number
+	Revisions FnRevisions `json:"revisions" redis:"revisions"`
+	// Aliases are named routes splitting the invocations between revisions
+	Aliases FnAliases `json:"aliases" redis:"aliases"`
+	// MinInstances is the number of instances the autoscaler never scales down below
+	MinInstances int `json:"minInstances" redis:"minInstances"`
+	// MaxInstances is the number of instances the autoscaler never scales up above.
+	// If zero, the global default is applied.
+	MaxInstances int `json:"maxInstances" redis:"maxInstances"`
+	// IdleTimeout is the number of seconds an instance is kept warm after its last invocation.
+	// If zero, the global default is applied.
+	IdleTimeout int `json:"idleTimeout" redis:"idleTimeout"`
+}
+
+// KeepWarmDuration returns the duration an instance of the function is kept warm after its last
+// invocation, or the given default if the function doesn't define its own idle timeout.
+func (fn *Function) KeepWarmDuration(def time.Duration) time.Duration {
+	if fn.IdleTimeout > 0 {
+		return time.Duration(fn.IdleTimeout) * time.Second
+	}
+	return def
+}
+
+// MinWarmInstances returns the number of instances kept permanently warm for the revision the function
+// is pinned to. Only the latest revision is kept warm, the instances of the previous revisions expire.
+func (fn *Function) MinWarmInstances() int {
+	history := fn.History()
+	if len(history) > 0 && history[len(history)-1].Number != fn.Revision {
+		return 0
+	}
+	return fn.MinInstances
+}
+
+// FnRevision is an immutable snapshot of a function definition.
+// Each creation or update of a function produces a new revision.
+type FnRevision struct {
+	Number int `json:"number"`
+	// Id is the identifier of the revision into the orchestrator
+	Id        string    `json:"id"`
+	ImageURL  string    `json:"image"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// FnRevisions implements encoding.BinaryMarshaler so the revision history
+// can be stored as a single field of a Redis HSET.
+type FnRevisions []*FnRevision
+
+func (r FnRevisions) MarshalBinary() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r *FnRevisions) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, r)
+}
+
+// NewRevision returns a copy of the function with a new revision using the given image
+// appended to its history. The new revision becomes the current revision of the function,
+// but its orchestrator identifier is left empty until the revision is deployed.
+func (fn *Function) NewRevision(image string) *Function {
+	history := fn.History()
+
+	number := 1
+	if len(history) > 0 {
+		number = history[len(history)-1].Number + 1
+	}
+
+	revisions := make(FnRevisions, len(history), len(history)+1)
+	copy(revisions, history)
+
+	next := *fn
+	next.Id = ""
+	next.ImageURL = image
+	next.Revision = number
+	next.Revisions = append(revisions, &FnRevision{
+		Number:    number,
+		ImageURL:  image,
+		CreatedAt: time.Now().UTC(),
+	})
+
+	return &next
+}
+
+// History returns the revisions of the function. Functions registered before revisions
+// were introduced don't have any history, so their current definition is returned as
+// the first revision.
+func (fn *Function) History() FnRevisions {
+	if len(fn.Revisions) > 0 || fn.Id == "" {
+		return fn.Revisions
+	}
+
+	number := fn.Revision
+	if number == 0 {
+		number = 1
+	}
+
+	return FnRevisions{{Number: number, Id: fn.Id, ImageURL: fn.ImageURL}}
+}
+
+// Deployed records the orchestrator identifier of the current revision.
+func (fn *Function) Deployed(id string) {
+	fn.Id = id
+	if rev := fn.GetRevision(fn.Revision); rev != nil {
+		rev.Id = id
+	}
+}
+
+// GetRevision returns the revision with the given number, or nil if it doesn't exist.
+func (fn *Function) GetRevision(number int) *FnRevision {
+	for _, rev := range fn.History() {
+		if rev.Number == number {
+			return rev
+		}
+	}
+	return nil
+}
+
+// AtRevision returns a copy of the function pinned to the given revision,
+// or nil if the revision doesn't exist.
+func (fn *Function) AtRevision(number int) *Function {
+	rev := fn.GetRevision(number)
+	if rev == nil {
+		return nil
+	}
+
+	pinned := *fn
+	pinned.Id = rev.Id
+	pinned.ImageURL = rev.ImageURL
+	pinned.Revision = rev.Number
+	pinned.Revisions = fn.History()
+
+	return &pinned
+}
+
+// WithAlias returns a copy of the function with the given alias set. If the alias
+// is nil, the alias is removed from the copy.
+func (fn *Function) WithAlias(name string, alias *FnAlias) *Function {
+	aliases := FnAliases{}
+	for k, v := range fn.Aliases {
+		aliases[k] = v
+	}
+
+	if alias == nil {
+		delete(aliases, name)
+	} else {
+		aliases[name] = alias
+	}
+
+	next := *fn
+	next.Aliases = aliases
+	return &next
+}
+
+// FnAlias is a named route to one or more revisions of a function.
+// Each invocation of the alias is sent to one of its revisions, according to their weight.
+type FnAlias struct {
+	Routes []*FnAliasRoute `json:"routes"`
+}
+
+type FnAliasRoute struct {
+	Revision int `json:"revision"`
+	// Weight is the percentage of the invocations routed to the revision
+	Weight int `json:"weight"`
+}
+
+// Pick returns the revision number that must handle the next invocation of the alias.
+func (a *FnAlias) Pick() int {
+	total := 0
+	for _, route := range a.Routes {
+		total += route.Weight
+	}
+
+	n := rand.Intn(total)
+	for _, route := range a.Routes {
+		if n < route.Weight {
+			return route.Revision
+		}
+		n -= route.Weight
+	}
+
+	// Unreachable as long as the weights are positive
+	return a.Routes[len(a.Routes)-1].Revision
+}
+
+// FnAliases implements encoding.BinaryMarshaler so the aliases
+// can be stored as a single field of a Redis HSET.
+type FnAliases map[string]*FnAlias
+
+func (a FnAliases) MarshalBinary() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+func (a *FnAliases) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, a)
 }
 
 type FnInstance struct {
@@ -15,6 +218,63 @@ type FnInstance struct {
 	Endpoint *url.URL  `json:"endpoint"`
 }
 
+// Ref returns the reference of the instance.
+func (i *FnInstance) Ref() *FnInstanceRef {
+	return &FnInstanceRef{FunctionId: i.Function.Id, InstanceId: i.Id}
+}
+
+// Record returns the representation of the instance stored into the state.
+func (i *FnInstance) Record() *FnInstanceRecord {
+	return &FnInstanceRecord{
+		FunctionId:   i.Function.Id,
+		InstanceId:   i.Id,
+		FunctionName: i.Function.Name,
+		Revision:     i.Function.Revision,
+		Endpoint:     i.Endpoint.String(),
+	}
+}
+
+// FnInstanceRecord is the representation of a function instance stored into the state,
+// separately from the function definition.
+type FnInstanceRecord struct {
+	FunctionId   string `json:"functionId"`
+	InstanceId   string `json:"instanceId"`
+	FunctionName string `json:"functionName"`
+	Revision     int    `json:"revision"`
+	Endpoint     string `json:"endpoint"`
+}
+
+// Ref returns the reference of the recorded instance.
+func (r *FnInstanceRecord) Ref() *FnInstanceRef {
+	return &FnInstanceRef{FunctionId: r.FunctionId, InstanceId: r.InstanceId}
+}
+
+// fnInstanceRefSeparator separates the function and instance identifiers in the string form of a reference
+const fnInstanceRefSeparator = "/"
+
+var ErrInvalidFnInstanceRef = errors.New("invalid function instance reference")
+
+// FnInstanceRef identifies an instance of a function revision without holding its full definition.
+type FnInstanceRef struct {
+	// FunctionId is the orchestrator identifier of the function revision run by the instance
+	FunctionId string `json:"functionId"`
+	InstanceId string `json:"instanceId"`
+}
+
+// String returns the reference in the form <function id>/<instance id>.
+func (r *FnInstanceRef) String() string {
+	return r.FunctionId + fnInstanceRefSeparator + r.InstanceId
+}
+
+// ParseFnInstanceRef parses a reference previously formatted with FnInstanceRef.String.
+func ParseFnInstanceRef(s string) (*FnInstanceRef, error) {
+	functionId, instanceId, found := strings.Cut(s, fnInstanceRefSeparator)
+	if !found || functionId == "" || instanceId == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFnInstanceRef, s)
+	}
+	return &FnInstanceRef{FunctionId: functionId, InstanceId: instanceId}, nil
+}
+
 type FunctionProcessMetadata struct {
 	ExecutionTimeMs int `json:"execution_time_ms"`
 	// Array of strings containing the logs of the function execution
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFnInstanceRefRoundTrip(t *testing.T) {
+	refs := []*FnInstanceRef{
+		{FunctionId: "weatho-r2", InstanceId: "weatho-r2-x7k2p"},
+		{FunctionId: "0f1c9a4e-5b7d-4e1f-9c3a-2d8b6e4f1a07", InstanceId: "5e2d7c1b-8a3f-4b6e-9d0c-1f2e3a4b5c6d"},
+		// Only the first separator delimits the function identifier
+		{FunctionId: "fn", InstanceId: "nested/instance"},
+	}
+
+	for _, ref := range refs {
+		parsed, err := ParseFnInstanceRef(ref.String())
+		if err != nil {
+			t.Fatalf("ParseFnInstanceRef(%q) returned an error: %v", ref.String(), err)
+		}
+		if *parsed != *ref {
+			t.Errorf("ParseFnInstanceRef(%q) = %+v, want %+v", ref.String(), parsed, ref)
+		}
+	}
+}
+
+func TestParseFnInstanceRefInvalid(t *testing.T) {
+	inputs := []string{"", "/", "fn", "fn/", "/instance"}
+
+	for _, input := range inputs {
+		ref, err := ParseFnInstanceRef(input)
+		if !errors.Is(err, ErrInvalidFnInstanceRef) {
+			t.Errorf("ParseFnInstanceRef(%q) = %+v, %v, want ErrInvalidFnInstanceRef", input, ref, err)
+		}
+	}
+}